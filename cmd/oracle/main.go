@@ -3,6 +3,8 @@ package main
 import (
 	"chainlink-lite/config"
 	"context"
+	"flag"
+	"fmt"
 	"os/signal"
 	"syscall"
 	"time"
@@ -10,14 +12,37 @@ import (
 	"chainlink-lite/internal/app/domain"
 	"chainlink-lite/internal/app/service"
 	"chainlink-lite/internal/app/usecase"
+	"chainlink-lite/internal/infra/committee"
 	"chainlink-lite/internal/infra/db"
-	"chainlink-lite/internal/infra/eth"
+	"chainlink-lite/internal/infra/jobs"
+	"chainlink-lite/internal/infra/keystore"
+	"chainlink-lite/internal/infra/remotesigner"
+	"chainlink-lite/internal/infra/source"
 
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
 	log "github.com/sirupsen/logrus"
 	"golang.org/x/exp/rand"
 )
 
 func main() {
+	printIdentity := flag.Bool("identity", false, "Print the node ID and public key to list in the committee file, then exit")
+	rotate := flag.Bool("rotate-key", false, "Replace the node key with a successor key and record the rotation announcement, then exit")
+	flag.Parse()
+
+	if *printIdentity {
+		if err := identity(); err != nil {
+			log.Fatalf("Unable to load identity: %v", err)
+		}
+		return
+	}
+	if *rotate {
+		if err := rotateKey(); err != nil {
+			log.Fatalf("Unable to rotate key: %v", err)
+		}
+		return
+	}
+
 	// Sleep for a random number of seconds to avoid multiple nodes starting at the same time
 	time.Sleep(time.Duration(rand.Intn(10)) * time.Second)
 
@@ -33,6 +58,50 @@ func main() {
 
 	log.SetLevel(log.Level(cfg.LogLevel))
 
+	// Reports of the secp256k1-eip712 scheme are signed and verified in the domain of the chain and contract
+	if err := service.ConfigureEIP712Domain(domain.SignatureScheme(cfg.Signer.Scheme), service.EIP712Domain{
+		ChainID:           cfg.Signer.EIP712ChainID,
+		VerifyingContract: cfg.Signer.EIP712VerifyingContract,
+	}); err != nil {
+		log.Fatalf("Invalid signer configuration: %v", err)
+	}
+
+	// Job files declare more feeds, observed by their task pipelines
+	jobSpecs := make(map[string]domain.JobSpec)
+	if cfg.Jobs.Dir != "" {
+		specs, err := jobs.LoadDir(cfg.Jobs.Dir)
+		if err != nil {
+			log.Fatalf("Unable to load jobs: %v", err)
+		}
+		for _, spec := range specs {
+			cfg.Feeds = append(cfg.Feeds, config.Feed{
+				Name:               spec.Feed,
+				Topic:              spec.Topic,
+				Interval:           spec.Schedule,
+				WriteQuorum:        spec.WriteQuorum,
+				Decimals:           spec.Decimals,
+				DeviationThreshold: spec.DeviationThreshold,
+				Heartbeat:          spec.Heartbeat,
+			})
+			jobSpecs[spec.Feed] = spec
+		}
+		log.Infof("Loaded %d jobs from %s", len(specs), cfg.Jobs.Dir)
+	}
+
+	// Every feed is published on its own topic
+	topics, err := feedTopics(cfg.Feeds)
+	if err != nil {
+		log.Fatalf("Invalid feeds: %v", err)
+	}
+	// Feeds without write triggers are written every round, at most every min interval
+	policies := make(map[string]domain.WritePolicy, len(cfg.Feeds))
+	for _, feed := range cfg.Feeds {
+		policies[feed.Name], err = writePolicy(feed, cfg.PubSub.MinIntervalBetweenWrites)
+		if err != nil {
+			log.Fatalf("Invalid write triggers: %v", err)
+		}
+	}
+
 	// Create a the database repository
 	repo, err := db.NewPriceMessageRepository(ctx, cfg.Database.URL)
 	if err != nil {
@@ -40,13 +109,89 @@ func main() {
 	}
 	defer repo.Close(ctx)
 
-	signer, err := service.NewSignerService()
+	// Task runs are only recorded for debugging, on a connection of their own
+	var taskRuns *db.PgTaskRunRepository
+	if len(jobSpecs) > 0 {
+		taskRuns, err = db.NewTaskRunRepository(ctx, cfg.Database.URL, cfg.Jobs.TaskRunRetention)
+		if err != nil {
+			log.Fatalf("Unable to create task run repository: %v", err)
+		}
+		defer taskRuns.Close(ctx)
+	}
+
+	// Load the node key from the keystore, creating it on first run
+	key, err := loadNodeKey(cfg)
+	if err != nil {
+		log.Fatalf("Unable to load keystore %s: %v", cfg.Keystore.Path, err)
+	}
+
+	// Sign with the node key, or with a key held by a separate signer process
+	var signer domain.Signer
+	switch cfg.Signer.Type {
+	case "remote":
+		var token string
+		token, err = remotesigner.ReadToken(cfg.Signer.TokenEnv, cfg.Signer.TokenFile)
+		if err == nil {
+			signer, err = remotesigner.NewRemoteSigner(ctx, cfg.Signer.RemoteURL, token, cfg.Signer.Timeout)
+		}
+	default:
+		signer, err = service.NewSignerService(key, domain.SignatureScheme(cfg.Signer.Scheme))
+	}
 	if err != nil {
 		log.Fatalf("Unable to create signer service: %v", err)
 	}
 
+	// Load the committee of trusted nodes, the leaders of the rounds are taken from it
+	if cfg.Committee.Path == "" {
+		log.Fatal("No committee configured, the nodes need one to agree on the leaders of the rounds")
+	}
+	trusted, err := committee.Load(cfg.Committee.Path)
+	if err != nil {
+		log.Fatalf("Unable to load committee: %v", err)
+	}
+	log.Infof("Enforcing committee version %d with %d members", trusted.Version, trusted.Size())
+
+	// Load the revoked keys and the key rotations of this node
+	var revocations domain.RevocationList
+	if cfg.Keys.RevocationPath != "" {
+		revocations, err = committee.LoadRevocations(cfg.Keys.RevocationPath)
+		if err != nil {
+			log.Fatalf("Unable to load revocation list: %v", err)
+		}
+		log.Infof("Ignoring %d revoked keys", len(revocations.Revoked))
+	}
+	keys := service.NewKeyRegistry(trusted, revocations, cfg.Keys.Overlap, cfg.Keys.MaxRotationSkew)
+	rotations, err := keystore.LoadRotations(cfg.Keys.RotationsPath)
+	if err != nil {
+		log.Fatalf("Unable to load key rotations: %v", err)
+	}
+	for _, rotation := range rotations {
+		if _, err := keys.AddRotation(rotation); err != nil {
+			log.Warnf("Ignoring key rotation from %s to %s: %v", rotation.OldPeerID, rotation.NewPeerID, err)
+		}
+	}
+	if !keys.IsKnown(signer.ID()) {
+		log.Warnf("This node (%s) is not an active committee member, its signatures will be ignored", signer.ID())
+	}
+
+	// Aggregate co-signatures with a BLS key derived from the node key
+	var blsSigner *service.BLSSigner
+	if cfg.Signer.BLSAggregation {
+		if cfg.Signer.Type == "remote" {
+			log.Fatal("BLS aggregation is not supported with a remote signer")
+		}
+		blsSigner, err = service.NewBLSSigner(key)
+		if err != nil {
+			log.Fatalf("Unable to derive BLS key: %v", err)
+		}
+	}
+	reportSigner, err := service.NewReportSigner(signer, blsSigner, trusted)
+	if err != nil {
+		log.Fatalf("Unable to create report signer: %v", err)
+	}
+
 	// Create a node and discovery service
-	node, err := service.NewNode(ctx, cfg.PubSub.TopicName, signer.GetPrivateKey(), cfg.PubSub.Port)
+	node, err := service.NewNode(ctx, topics, key, cfg.PubSub.Port)
 	if err != nil {
 		log.Fatalf("Unable to create node: %v", err)
 	}
@@ -54,33 +199,370 @@ func main() {
 
 	log.Info("Node created: ", node.Host.ID())
 
+	// The peers send signing requests to the host listed for the signer in the committee
+	if node.Host.ID().String() != signer.ID() {
+		if m, ok := trusted.Member(signer.ID()); ok && m.Host() != node.Host.ID().String() {
+			log.Fatalf("Signing as %s from host %s, set the host_peer_id of %s to %s in the committee file",
+				signer.ID(), node.Host.ID(), signer.ID(), node.Host.ID())
+		}
+	}
+
 	discovery := service.NewDiscoveryService(ctx, node, cfg.PubSub.DiscoverPeersInterval)
 	// Start the discovery service
 	go discovery.FindPeers()
 	// Advertise the node
 	discovery.Advertise()
 
-	// Create a pubsub service
-	pubsub, err := service.NewPubSubService(ctx, cfg.PubSub.TopicName, node.Host)
+	// Every feed has its own topics on a single GossipSub router
+	gossip, err := service.NewGossipSub(ctx, node.Host, topics)
 	if err != nil {
-		log.Fatalf("Unable to create pubsub service: %v", err)
+		log.Fatalf("Unable to create gossipsub: %v", err)
 	}
-	defer pubsub.Close()
 
-	var priceTicker domain.EthPriceTicker
-	if cfg.PriceTicker.Mock {
-		priceTicker = eth.NewMockTicker()
-	} else {
-		priceTicker = eth.NewEthPriceTicker(cfg.PriceTicker.URL)
+	// The price sources observe the feeds without a job
+	var priceSource domain.PriceSource
+	if len(jobSpecs) < len(cfg.Feeds) {
+		if cfg.PriceSource.Mock {
+			priceSource = source.NewMockSource()
+		} else {
+			priceSource, err = medianSource(cfg.PriceSource, cfg.Feeds)
+			if err != nil {
+				log.Fatalf("Invalid price sources: %v", err)
+			}
+		}
+	}
+	// Jobs fetch prices with the source tasks and compute on them with the service tasks
+	tasks := service.PipelineTasks()
+	for taskType, factory := range source.PipelineTasks() {
+		tasks[taskType] = factory
 	}
 
-	// Create a publisher and subscriber
-	publisher := usecase.NewPublisher(priceTicker, cfg.PubSub.FetchPriceInterval, pubsub, signer)
-	subscriber := usecase.NewSubscriber(pubsub, repo, cfg.PubSub.MinSignaturesToWrite, cfg.PubSub.MinIntervalBetweenWrites, signer)
+	// Answer the signing requests of the leaders of every feed on a single stream protocol
+	signing := service.NewSignProtocol(node.Host, keys)
+	signing.Serve(ctx)
+	defer signing.Close()
 
-	// Start the publisher and subscriber
-	go publisher.Start(ctx)
-	go subscriber.Start(ctx)
+	// Observations and pending reports are keyed by feed, and shared by every feed
+	// A round is pooled at most during the current and the next round of the slowest feed
+	var longestInterval time.Duration
+	for _, feed := range cfg.Feeds {
+		longestInterval = max(longestInterval, feed.Interval)
+	}
+	observations := service.NewObservationPool(2 * longestInterval)
+	pending, err := service.NewPendingReports(keys, cfg.PubSub.PendingReportTTL, cfg.PubSub.SeenReportsCacheSize)
+	if err != nil {
+		log.Fatalf("Unable to create pending reports: %v", err)
+	}
+	go pending.Start(ctx)
+
+	// Serve every feed concurrently
+	pubsubs := make(map[string]*service.PubSubService, len(cfg.Feeds))
+	for _, feed := range cfg.Feeds {
+		quorum, err := domain.ParseQuorum(feed.WriteQuorum)
+		if err != nil {
+			log.Fatalf("Invalid write quorum of %s: %v", feed.Name, err)
+		}
+
+		schedule, err := service.NewSchedule(keys, feed.Interval, cfg.PubSub.CommitWindow, cfg.PubSub.ObservationWindow, cfg.PubSub.LeaderTimeout)
+		if err != nil {
+			log.Fatalf("Unable to create round schedule of %s: %v", feed.Name, err)
+		}
+		quorumTracker := service.NewQuorumTracker(feed.Name, keys, quorum)
+		validator := service.NewMessageValidator(keys, feed.Name, schedule, observations, quorumTracker, *feed.Decimals,
+			cfg.PubSub.MinObservations, cfg.PubSub.MaxMessageAge)
+		pubsub, err := service.NewPubSubService(ctx, gossip, feed.Topic, node.Host, validator)
+		if err != nil {
+			log.Fatalf("Unable to create pubsub service of %s: %v", feed.Name, err)
+		}
+		defer pubsub.Close()
+		pubsubs[feed.Name] = pubsub
+
+		// The feed of a job is observed by its pipeline, which also fetches the reference prices of the feed
+		var observer usecase.Observer
+		reference := priceSource
+		if spec, ok := jobSpecs[feed.Name]; ok {
+			if spec.Timeout == 0 {
+				spec.Timeout = cfg.PriceSource.Timeout
+			}
+			job, err := usecase.NewJob(spec, tasks, schedule, pubsub, reportSigner, taskRuns)
+			if err != nil {
+				log.Fatalf("Unable to create job %s: %v", spec.Name, err)
+			}
+			observer, reference = job, job
+		} else {
+			observer = usecase.NewSourceObserver(priceSource, feed.Name, *feed.Decimals, schedule, pubsub, reportSigner)
+		}
+
+		// Check the reports of the feed against the observation of the node,
+		// or a price fetched at most one round ago
+		references := service.NewReferencePrices(reference, feed.Name, reportSigner.ID(), keys, observations, feed.Interval)
+		cosigner := usecase.NewCosigner(validator, repo, references, cfg.PubSub.PriceTolerance, reportSigner)
+		signing.Handle(feed.Name, cosigner.Sign)
+
+		// Create a publisher and subscriber
+		publisher := usecase.NewPublisher(observer, feed.Name, schedule, cfg.PubSub.MinObservations, pubsub, observations, signing,
+			quorumTracker, pending, reportSigner)
+		subscriber := usecase.NewSubscriber(pubsub, repo, keys, quorumTracker, policies[feed.Name], cfg.PubSub.WriterTimeout, pending, reportSigner)
+
+		// Start the publisher and subscriber
+		go publisher.Start(ctx)
+		go subscriber.Start(ctx)
+
+		// Keep announcing the key rotations of this node, so peers accept its current key
+		if len(rotations) > 0 {
+			go usecase.NewRotationAnnouncer(pubsub, rotations, cfg.Keys.AnnounceInterval).Start(ctx)
+		}
+		log.Infof("Serving %s on topic %s every %s", feed.Name, feed.Topic, feed.Interval)
+	}
+
+	// Log the counters of the node while it runs, and once more when it stops
+	if cfg.StatusInterval > 0 {
+		go func() {
+			ticker := time.NewTicker(cfg.StatusInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					logStatus(pubsubs, pending, observations, cfg.PubSub.CommitWindow > 0)
+				}
+			}
+		}()
+	}
 
 	<-ctx.Done()
+	logStatus(pubsubs, pending, observations, cfg.PubSub.CommitWindow > 0)
+}
+
+// logStatus logs the messages rejected by the topic validator of every feed, the finalized and expired reports,
+// and the missed reveals of every node if rounds have a commit window
+func logStatus(pubsubs map[string]*service.PubSubService, pending *service.PendingReports, observations *service.ObservationPool,
+	commitReveal bool) {
+	for feed, pubsub := range pubsubs {
+		log.Infof("Rejected messages of %s per reason: %v", feed, pubsub.RejectionCounts())
+	}
+	finalized, expired := pending.Counts()
+	log.Infof("Reports finalized: %d, expired before reaching the quorum: %d", finalized, expired)
+	if commitReveal {
+		log.Info("Missed reveals per node: ", observations.MissedReveals())
+	}
+}
+
+// feedTopics returns the topics of the feeds
+// Every feed must have a distinct name and topic, so reports of different feeds never share a topic
+func feedTopics(feeds []config.Feed) ([]string, error) {
+	if len(feeds) == 0 {
+		return nil, fmt.Errorf("no feed configured")
+	}
+	names := make(map[string]struct{}, len(feeds))
+	topics := make([]string, 0, len(feeds))
+	for _, feed := range feeds {
+		if _, _, err := domain.ParseFeed(feed.Name); err != nil {
+			return nil, err
+		}
+		if feed.Decimals == nil {
+			return nil, fmt.Errorf("feed %s has no decimals", feed.Name)
+		}
+		if *feed.Decimals > domain.MaxPriceDecimals {
+			return nil, fmt.Errorf("feed %s has %d decimals, at most %d", feed.Name, *feed.Decimals, domain.MaxPriceDecimals)
+		}
+		if _, ok := names[feed.Name]; ok {
+			return nil, fmt.Errorf("feed %s is configured twice", feed.Name)
+		}
+		names[feed.Name] = struct{}{}
+		for _, topic := range topics {
+			if topic == feed.Topic {
+				return nil, fmt.Errorf("topic %s is configured for two feeds", feed.Topic)
+			}
+		}
+		if feed.Topic == "" {
+			return nil, fmt.Errorf("feed %s has no topic", feed.Name)
+		}
+		topics = append(topics, feed.Topic)
+	}
+	return topics, nil
+}
+
+// writePolicy returns the write policy of the feed, with the minimum interval between writes of every feed
+func writePolicy(feed config.Feed, minInterval time.Duration) (domain.WritePolicy, error) {
+	policy := domain.WritePolicy{MinInterval: minInterval, Heartbeat: feed.Heartbeat}
+	if feed.DeviationThreshold != "" {
+		threshold, err := domain.ParseDecimal(feed.DeviationThreshold)
+		if err != nil {
+			return domain.WritePolicy{}, fmt.Errorf("feed %s: deviation threshold: %w", feed.Name, err)
+		}
+		policy.DeviationThreshold = threshold
+	}
+	if feed.Heartbeat < 0 {
+		return domain.WritePolicy{}, fmt.Errorf("feed %s: negative heartbeat %s", feed.Name, feed.Heartbeat)
+	}
+	return policy, nil
+}
+
+// medianSource returns the median of the enabled price sources
+func medianSource(cfg config.PriceSource, feeds []config.Feed) (*source.Median, error) {
+	ids := make(map[string]string, len(feeds))
+	symbols := map[string]map[string]string{"binance": {}, "coinbase": {}, "kraken": {}}
+	for _, spec := range cfg.HTTP {
+		if _, ok := symbols[spec.Name]; ok || spec.Name == "coingecko" || spec.Name == "cryptocompare" {
+			return nil, fmt.Errorf("http source name %s is already used by another source", spec.Name)
+		}
+		symbols[spec.Name] = map[string]string{}
+	}
+	for _, feed := range feeds {
+		if feed.CoinGeckoID != "" {
+			ids[feed.Name] = feed.CoinGeckoID
+		}
+		for name, symbol := range feed.Symbols {
+			if _, ok := symbols[name]; !ok {
+				return nil, fmt.Errorf("feed %s has a symbol for unknown source %s", feed.Name, name)
+			}
+			symbols[name][feed.Name] = symbol
+		}
+	}
+
+	sources := make(map[string]domain.PriceSource)
+	if cfg.CoinGeckoURL != "" {
+		sources["coingecko"] = source.NewCoinGecko(cfg.CoinGeckoURL, ids)
+	}
+	if cfg.BinanceURL != "" {
+		sources["binance"] = source.NewBinance(cfg.BinanceURL, symbols["binance"])
+	}
+	if cfg.CoinbaseURL != "" {
+		sources["coinbase"] = source.NewCoinbase(cfg.CoinbaseURL, symbols["coinbase"])
+	}
+	if cfg.KrakenURL != "" {
+		sources["kraken"] = source.NewKraken(cfg.KrakenURL, symbols["kraken"])
+	}
+	if cfg.CryptoCompareURL != "" {
+		sources["cryptocompare"] = source.NewCryptoCompare(cfg.CryptoCompareURL)
+	}
+	for _, spec := range cfg.HTTP {
+		httpSource, err := source.NewHTTPSource(source.HTTPSpec{
+			Name:       spec.Name,
+			Method:     spec.Method,
+			URL:        spec.URL,
+			Headers:    spec.Headers,
+			Body:       spec.Body,
+			Path:       spec.Path,
+			Multiplier: spec.Multiplier,
+			APIKeyEnv:  spec.APIKeyEnv,
+			Feeds:      spec.Feeds,
+			Symbols:    symbols[spec.Name],
+		})
+		if err != nil {
+			return nil, err
+		}
+		sources[spec.Name] = httpSource
+	}
+	return source.NewMedian(sources, cfg.MinSources, cfg.Timeout)
+}
+
+// loadNodeKey loads the node key from the keystore, creating a key of the type of the signature scheme on first run
+func loadNodeKey(cfg config.Config) (crypto.PrivKey, error) {
+	keyType, err := service.KeyTypeForScheme(domain.SignatureScheme(cfg.Signer.Scheme))
+	if err != nil {
+		return nil, err
+	}
+	passphrase, err := keystore.ReadPassphrase(cfg.Keystore.PassphraseEnv, cfg.Keystore.PassphraseFile)
+	if err != nil {
+		return nil, err
+	}
+	return keystore.LoadOrCreate(cfg.Keystore.Path, passphrase, keyType)
+}
+
+// identity prints the committee member entry of the node key
+func identity() error {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return err
+	}
+
+	key, err := loadNodeKey(cfg)
+	if err != nil {
+		return err
+	}
+
+	// A remote signer signs as its own key, from the host of the node key
+	if cfg.Signer.Type == "remote" {
+		token, err := remotesigner.ReadToken(cfg.Signer.TokenEnv, cfg.Signer.TokenFile)
+		if err != nil {
+			return err
+		}
+		signer, err := remotesigner.NewRemoteSigner(context.Background(), cfg.Signer.RemoteURL, token, cfg.Signer.Timeout)
+		if err != nil {
+			return err
+		}
+		hostID, err := peer.IDFromPrivateKey(key)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("- peer_id: %q\n  host_peer_id: %q\n  public_key: %q\n", signer.ID(), hostID, signer.PublicKey())
+		return nil
+	}
+
+	signer, err := service.NewSignerService(key, domain.SignatureScheme(cfg.Signer.Scheme))
+	if err != nil {
+		return err
+	}
+
+	blsSigner, err := service.NewBLSSigner(key)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("- peer_id: %q\n  public_key: %q\n  bls_public_key: %q\n  bls_pop: %q\n",
+		signer.ID(), signer.PublicKey(), blsSigner.PublicKey(), blsSigner.ProofOfPossession(signer.ID()))
+	return nil
+}
+
+// rotateKey replaces the node key with a successor key of the same signature scheme
+// The rotation announcement, signed by both keys, is published by the node from its next start
+func rotateKey() error {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return err
+	}
+	if cfg.Signer.Type == "remote" {
+		return fmt.Errorf("the key of a remote signer is held by the signer server")
+	}
+	if cfg.Signer.BLSAggregation {
+		return fmt.Errorf("BLS aggregation indexes the committee file, publish a new committee version instead")
+	}
+
+	oldKey, err := loadNodeKey(cfg)
+	if err != nil {
+		return err
+	}
+	keyType, err := service.KeyTypeForScheme(domain.SignatureScheme(cfg.Signer.Scheme))
+	if err != nil {
+		return err
+	}
+	newKey, _, err := crypto.GenerateKeyPair(keyType, -1)
+	if err != nil {
+		return err
+	}
+	rotation, err := service.NewKeyRotation(oldKey, newKey, time.Now())
+	if err != nil {
+		return err
+	}
+	passphrase, err := keystore.ReadPassphrase(cfg.Keystore.PassphraseEnv, cfg.Keystore.PassphraseFile)
+	if err != nil {
+		return err
+	}
+
+	// Keep the old key and record the announcement before the keystore is replaced
+	if err := keystore.Save(cfg.Keystore.Path+"."+rotation.OldPeerID, passphrase, oldKey); err != nil {
+		return err
+	}
+	if err := keystore.AppendRotation(cfg.Keys.RotationsPath, rotation); err != nil {
+		return err
+	}
+	if err := keystore.Save(cfg.Keystore.Path, passphrase, newKey); err != nil {
+		return err
+	}
+
+	fmt.Printf("Rotated key from %s to %s, restart the node to announce the rotation\n", rotation.OldPeerID, rotation.NewPeerID)
+	return nil
 }
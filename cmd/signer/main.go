@@ -0,0 +1,127 @@
+package main
+
+import (
+	"chainlink-lite/config"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"chainlink-lite/internal/app/domain"
+	"chainlink-lite/internal/app/service"
+	"chainlink-lite/internal/infra/keystore"
+	"chainlink-lite/internal/infra/remotesigner"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Reference signer server, run next to the nodes in place of a KMS or HSM
+func main() {
+	// Create a context that is canceled when a signal is received
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// Load configuration
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Unable to load configuration: %v", err)
+	}
+
+	log.SetLevel(log.Level(cfg.LogLevel))
+
+	// The signature scheme decides the type of the key
+	scheme := domain.SignatureScheme(cfg.Signer.Scheme)
+	keyType, err := service.KeyTypeForScheme(scheme)
+	if err != nil {
+		log.Fatalf("Invalid signer configuration: %v", err)
+	}
+	if err := service.ConfigureEIP712Domain(domain.SignatureScheme(cfg.Signer.Scheme), service.EIP712Domain{
+		ChainID:           cfg.Signer.EIP712ChainID,
+		VerifyingContract: cfg.Signer.EIP712VerifyingContract,
+	}); err != nil {
+		log.Fatalf("Invalid signer configuration: %v", err)
+	}
+
+	// Load the signing key from the keystore, creating it on first run
+	passphrase, err := keystore.ReadPassphrase(cfg.Keystore.PassphraseEnv, cfg.Keystore.PassphraseFile)
+	if err != nil {
+		log.Fatalf("Unable to read keystore passphrase: %v", err)
+	}
+	key, err := keystore.LoadOrCreate(cfg.SignerServer.KeystorePath, passphrase, keyType)
+	if err != nil {
+		log.Fatalf("Unable to load keystore %s: %v", cfg.SignerServer.KeystorePath, err)
+	}
+
+	signer, err := service.NewSignerService(key, scheme)
+	if err != nil {
+		log.Fatalf("Unable to create signer service: %v", err)
+	}
+	if address, err := service.EthereumAddress(key.GetPublic()); err == nil {
+		log.Info("Signer ethereum address: ", address)
+	}
+
+	// Every request must present the bearer token shared with the nodes
+	token, err := remotesigner.ReadToken(cfg.SignerServer.TokenEnv, cfg.SignerServer.TokenFile)
+	if err != nil {
+		log.Fatalf("Unable to read signer token: %v", err)
+	}
+	bounds, err := priceBounds(cfg.SignerServer.PriceBounds)
+	if err != nil {
+		log.Fatalf("Invalid signer server configuration: %v", err)
+	}
+	server, err := remotesigner.NewServer(signer, remotesigner.Policy{
+		RateLimit:       cfg.SignerServer.RateLimit,
+		Bounds:          bounds,
+		MaxDeviation:    cfg.SignerServer.MaxDeviation,
+		DeviationWindow: cfg.SignerServer.DeviationWindow,
+	}, token)
+	if err != nil {
+		log.Fatalf("Unable to create signer server: %v", err)
+	}
+	httpServer := &http.Server{
+		Addr:              cfg.SignerServer.ListenAddr,
+		Handler:           server.Handler(),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			log.Warnf("Failed to shut down signer server: %v", err)
+		}
+	}()
+
+	log.Infof("Signer %s listening on %s", signer.ID(), cfg.SignerServer.ListenAddr)
+	if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatalf("Signer server failed: %v", err)
+	}
+}
+
+// priceBounds returns the price bounds of the signer server by feed
+func priceBounds(configured []config.PriceBounds) (map[string]remotesigner.PriceBounds, error) {
+	bounds := make(map[string]remotesigner.PriceBounds, len(configured))
+	for _, b := range configured {
+		if _, ok := bounds[b.Feed]; ok {
+			return nil, fmt.Errorf("duplicate price bounds of %s", b.Feed)
+		}
+		var feedBounds remotesigner.PriceBounds
+		var err error
+		if b.Min != "" {
+			if feedBounds.Min, err = domain.ParseDecimal(b.Min); err != nil {
+				return nil, fmt.Errorf("minimum price of %s: %w", b.Feed, err)
+			}
+		}
+		if b.Max != "" {
+			if feedBounds.Max, err = domain.ParseDecimal(b.Max); err != nil {
+				return nil, fmt.Errorf("maximum price of %s: %w", b.Feed, err)
+			}
+		}
+		bounds[b.Feed] = feedBounds
+	}
+	return bounds, nil
+}
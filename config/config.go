@@ -8,26 +8,138 @@ import (
 )
 
 type Config struct {
-	Database    Database    `mapstructure:"database"`
-	PriceTicker PriceTicker `mapstructure:"price_ticker"`
-	PubSub      PubSub      `mapstructure:"pubsub"`
-	LogLevel    int         `mapstructure:"log_level"`
+	Database     Database     `mapstructure:"database"`
+	PriceSource  PriceSource  `mapstructure:"price_source"`
+	Feeds        []Feed       `mapstructure:"feeds"`
+	Jobs         Jobs         `mapstructure:"jobs"`
+	PubSub       PubSub       `mapstructure:"pubsub"`
+	Keystore     Keystore     `mapstructure:"keystore"`
+	Signer       Signer       `mapstructure:"signer"`
+	SignerServer SignerServer `mapstructure:"signer_server"`
+	Committee    Committee    `mapstructure:"committee"`
+	Keys         Keys         `mapstructure:"keys"`
+	LogLevel     int          `mapstructure:"log_level"`
+	// Interval between two logs of the counters of the node, 0 only logs them when the node stops
+	StatusInterval time.Duration `mapstructure:"status_interval"`
 }
 
 type Database struct {
 	URL string `mapstructure:"url"`
 }
 
-type PriceTicker struct {
-	URL  string `mapstructure:"url"`
-	Mock bool   `mapstructure:"mock"`
+// PriceSource are the APIs prices are fetched from, a source with an empty URL is disabled
+// The price observed by the node is the median of the sources, of which MinSources must return a price within Timeout
+type PriceSource struct {
+	CoinGeckoURL     string        `mapstructure:"coingecko_url"`
+	BinanceURL       string        `mapstructure:"binance_url"`
+	CoinbaseURL      string        `mapstructure:"coinbase_url"`
+	KrakenURL        string        `mapstructure:"kraken_url"`
+	CryptoCompareURL string        `mapstructure:"cryptocompare_url"`
+	HTTP             []HTTPSource  `mapstructure:"http"`
+	MinSources       int           `mapstructure:"min_sources"`
+	Timeout          time.Duration `mapstructure:"timeout"`
+	Mock             bool          `mapstructure:"mock"`
+}
+
+// HTTPSource is a price source on any HTTP API answering JSON, declared without code
+// URL, Headers, Body and Path are templates of the request of a feed, and Path is a JMESPath expression of the price
+type HTTPSource struct {
+	Name       string            `mapstructure:"name"`
+	Method     string            `mapstructure:"method"`
+	URL        string            `mapstructure:"url"`
+	Headers    map[string]string `mapstructure:"headers"`
+	Body       string            `mapstructure:"body"`
+	Path       string            `mapstructure:"path"`
+	Multiplier string            `mapstructure:"multiplier"`
+	APIKeyEnv  string            `mapstructure:"api_key_env"`
+	Feeds      []string          `mapstructure:"feeds"`
+}
+
+// Feed is a price feed served by the node, with its own topic, round interval, write quorum and decimals
+// Decimals are the decimals of the fixed-point prices of the feed, as the decimals of an EVM aggregator, required as 0 is a valid value
+// CoinGeckoID is the CoinGecko ID of the base asset of the feed, the quote asset is the vs currency
+// Symbols overrides the symbol of the feed on an exchange, by source name (binance, coinbase, kraken or an http source)
+// DeviationThreshold and Heartbeat are the write triggers of the feed, a decimal percentage and a duration, empty or 0 disables them
+type Feed struct {
+	Name               string            `mapstructure:"name"`
+	Topic              string            `mapstructure:"topic"`
+	Interval           time.Duration     `mapstructure:"interval"`
+	WriteQuorum        string            `mapstructure:"write_quorum"`
+	Decimals           *uint8            `mapstructure:"decimals"`
+	CoinGeckoID        string            `mapstructure:"coingecko_id"`
+	Symbols            map[string]string `mapstructure:"symbols"`
+	DeviationThreshold string            `mapstructure:"deviation_threshold"`
+	Heartbeat          time.Duration     `mapstructure:"heartbeat"`
+}
+
+// Jobs are the feeds declared by job files, observed by running their task pipelines instead of the price sources
+// TaskRunRetention is how long the task runs of the jobs are kept in the database, 0 keeps them forever
+type Jobs struct {
+	Dir              string        `mapstructure:"dir"`
+	TaskRunRetention time.Duration `mapstructure:"task_run_retention"`
+}
+
+type Keystore struct {
+	Path           string `mapstructure:"path"`
+	PassphraseEnv  string `mapstructure:"passphrase_env"`
+	PassphraseFile string `mapstructure:"passphrase_file"`
+}
+
+type Signer struct {
+	Type      string        `mapstructure:"type"`
+	Scheme    string        `mapstructure:"scheme"`
+	RemoteURL string        `mapstructure:"remote_url"`
+	TokenEnv  string        `mapstructure:"token_env"`
+	TokenFile string        `mapstructure:"token_file"`
+	Timeout   time.Duration `mapstructure:"timeout"`
+	// Aggregate co-signatures into a single BLS signature over the committee
+	BLSAggregation bool `mapstructure:"bls_aggregation"`
+	// EIP-712 domain of the secp256k1-eip712 scheme, the chain and contract that verify the reports
+	EIP712ChainID           uint64 `mapstructure:"eip712_chain_id"`
+	EIP712VerifyingContract string `mapstructure:"eip712_verifying_contract"`
+}
+
+type SignerServer struct {
+	ListenAddr      string        `mapstructure:"listen_addr"`
+	KeystorePath    string        `mapstructure:"keystore_path"`
+	TokenEnv        string        `mapstructure:"token_env"`
+	TokenFile       string        `mapstructure:"token_file"`
+	RateLimit       int           `mapstructure:"rate_limit"`
+	PriceBounds     []PriceBounds `mapstructure:"price_bounds"`
+	MaxDeviation    float64       `mapstructure:"max_deviation"`
+	DeviationWindow time.Duration `mapstructure:"deviation_window"`
+}
+
+// PriceBounds are the prices the signer server accepts for a feed, as decimals, empty disables the bound
+type PriceBounds struct {
+	Feed string `mapstructure:"feed"`
+	Min  string `mapstructure:"min"`
+	Max  string `mapstructure:"max"`
+}
+
+type Committee struct {
+	Path string `mapstructure:"path"`
+}
+
+type Keys struct {
+	Overlap          time.Duration `mapstructure:"overlap"`
+	MaxRotationSkew  time.Duration `mapstructure:"max_rotation_skew"`
+	AnnounceInterval time.Duration `mapstructure:"announce_interval"`
+	RotationsPath    string        `mapstructure:"rotations_path"`
+	RevocationPath   string        `mapstructure:"revocation_path"`
 }
 
 type PubSub struct {
-	TopicName                string        `mapstructure:"topic"`
-	FetchPriceInterval       time.Duration `mapstructure:"fetch_price_interval"`
-	MinSignaturesToWrite     int           `mapstructure:"min_signatures_to_write"`
+	CommitWindow             time.Duration `mapstructure:"commit_window"`
+	ObservationWindow        time.Duration `mapstructure:"observation_window"`
+	MinObservations          int           `mapstructure:"min_observations"`
+	LeaderTimeout            time.Duration `mapstructure:"leader_timeout"`
+	WriterTimeout            time.Duration `mapstructure:"writer_timeout"`
+	PendingReportTTL         time.Duration `mapstructure:"pending_report_ttl"`
+	SeenReportsCacheSize     int           `mapstructure:"seen_reports_cache_size"`
+	PriceTolerance           float64       `mapstructure:"price_tolerance"`
 	MinIntervalBetweenWrites time.Duration `mapstructure:"min_interval_between_writes"`
+	MaxMessageAge            time.Duration `mapstructure:"max_message_age"`
 	DiscoverPeersInterval    time.Duration `mapstructure:"discover_peers_interval"`
 	Port                     int           `mapstructure:"port"`
 }
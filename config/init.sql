@@ -1,13 +1,65 @@
-CREATE TABLE eth_price_messages (
+CREATE TABLE price_messages (
     id SERIAL PRIMARY KEY,
     message_id TEXT NOT NULL UNIQUE,
+    feed TEXT NOT NULL,
+    round BIGINT NOT NULL,
+    epoch INT NOT NULL,
     price NUMERIC NOT NULL,
+    trigger TEXT NOT NULL,
     publisher TEXT NOT NULL,
     writer TEXT NOT NULL,
     signers TEXT[] NOT NULL,
+    public_keys TEXT[] NOT NULL,
     signatures JSONB NOT NULL,
+    quorum INT NOT NULL,
+    observations JSONB NOT NULL,
+    payload_version SMALLINT NOT NULL,
+    aggregate_signature TEXT,
+    signer_bitmap TEXT,
+    committee_version INT,
     created_at TIMESTAMPTZ NOT NULL,
-    timestamp TIMESTAMPTZ NOT NULL
+    timestamp TIMESTAMPTZ NOT NULL,
+    UNIQUE (feed, round)
 );
 
-CREATE INDEX idx_messages_timestamp ON eth_price_messages (timestamp DESC);
+CREATE INDEX idx_messages_feed_timestamp ON price_messages (feed, timestamp DESC);
+
+CREATE TABLE evaluated_rounds (
+    feed TEXT PRIMARY KEY,
+    round BIGINT NOT NULL
+);
+
+CREATE TABLE publisher_evidence (
+    id SERIAL PRIMARY KEY,
+    reporter TEXT NOT NULL,
+    publisher TEXT NOT NULL,
+    feed TEXT NOT NULL,
+    round BIGINT NOT NULL,
+    epoch INT NOT NULL,
+    message_id TEXT NOT NULL,
+    proposed_price NUMERIC NOT NULL,
+    observed_price NUMERIC NOT NULL,
+    deviation DOUBLE PRECISION NOT NULL,
+    report JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    UNIQUE (message_id, reporter)
+);
+
+CREATE INDEX idx_evidence_publisher ON publisher_evidence (publisher);
+
+CREATE TABLE task_runs (
+    id SERIAL PRIMARY KEY,
+    job TEXT NOT NULL,
+    feed TEXT NOT NULL,
+    round BIGINT NOT NULL,
+    task_id TEXT NOT NULL,
+    task_type TEXT NOT NULL,
+    inputs JSONB NOT NULL,
+    output JSONB NOT NULL,
+    error TEXT,
+    started_at TIMESTAMPTZ NOT NULL,
+    finished_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_task_runs_job_round ON task_runs (job, round);
+CREATE INDEX idx_task_runs_started_at ON task_runs (started_at);
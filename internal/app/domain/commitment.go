@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+)
+
+// commitmentDomain separates commitment signatures from any other use of the node keys
+const commitmentDomain = "chainlink-lite/commitment"
+
+// Commitment binds a node to its observation of a round before the observation is revealed,
+// so a node can not copy the price observed by another node
+// Digest is the hex encoded hash of the salted observation, see CommitmentDigest
+// Observer is the node ID of the committing node
+// PublicKey is the hex encoded public key of the observer
+// Signature is the signature of the observer, tagged with its signature scheme
+// CreatedAt is the timestamp when the commitment was made
+type Commitment struct {
+	Round     int64  `json:"round" validate:"required"`
+	Feed      string `json:"feed" validate:"required"`
+	Digest    string `json:"digest" validate:"required,len=64,hexadecimal"`
+	Observer  string `json:"observer" validate:"required"`
+	PublicKey string `json:"public_key" validate:"required"`
+	Signature string `json:"signature" validate:"required"`
+	CreatedAt int64  `json:"timestamp" validate:"required"`
+}
+
+// SigningPayload returns the canonical encoding of the commitment that the observer signs
+func (c Commitment) SigningPayload() []byte {
+	buf := make([]byte, 0, 192)
+	buf = append(buf, commitmentDomain...)
+	buf = append(buf, 0)
+	buf = binary.BigEndian.AppendUint16(buf, SigningPayloadVersion)
+	buf = binary.BigEndian.AppendUint64(buf, uint64(c.Round))
+	for _, field := range []string{c.Feed, c.Digest, c.Observer} {
+		buf = binary.BigEndian.AppendUint32(buf, uint32(len(field)))
+		buf = append(buf, field...)
+	}
+	buf = binary.BigEndian.AppendUint64(buf, uint64(c.CreatedAt))
+	return buf
+}
+
+// CommitmentDigest returns the hex encoded hash an observer commits to before revealing the observation
+// The signing payload of the observation covers its salt, which keeps the price hidden until the reveal
+func CommitmentDigest(observation Observation) string {
+	digest := sha256.Sum256(observation.SigningPayload())
+	return hex.EncodeToString(digest[:])
+}
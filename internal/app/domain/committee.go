@@ -0,0 +1,73 @@
+package domain
+
+// Committee is the versioned allow-list of the oracle nodes trusted to publish and sign reports
+// Version is bumped every time the membership changes
+// Members are the trusted nodes
+type Committee struct {
+	Version int      `yaml:"version"`
+	Members []Member `yaml:"members"`
+}
+
+// Member is a trusted oracle node
+// Name is an optional human-readable name of the operator
+// PeerID is the node ID the member signs as
+// PublicKey is the hex encoded public key of the member
+// BLSPublicKey is the hex encoded BLS12-381 public key of the member, required for aggregate signatures
+// BLSProofOfPossession is the hex encoded signature of the BLS key over itself and the node ID, required with BLSPublicKey
+// HostPeerID is the libp2p host ID of the node of the member, when it differs from PeerID as it signs with a remote signer
+type Member struct {
+	Name                 string `yaml:"name"`
+	PeerID               string `yaml:"peer_id"`
+	HostPeerID           string `yaml:"host_peer_id"`
+	PublicKey            string `yaml:"public_key"`
+	BLSPublicKey         string `yaml:"bls_public_key"`
+	BLSProofOfPossession string `yaml:"bls_pop"`
+}
+
+// Member returns the committee member with the node ID
+func (c Committee) Member(peerID string) (Member, bool) {
+	i := c.Index(peerID)
+	if i < 0 {
+		return Member{}, false
+	}
+	return c.Members[i], true
+}
+
+// Index returns the position of the member in the committee, or -1 if the node ID is not listed
+func (c Committee) Index(peerID string) int {
+	for i, m := range c.Members {
+		if m.PeerID == peerID {
+			return i
+		}
+	}
+	return -1
+}
+
+// MemberByHost returns the committee member whose node runs on the libp2p host ID
+func (c Committee) MemberByHost(hostID string) (Member, bool) {
+	for _, m := range c.Members {
+		if m.Host() == hostID {
+			return m, true
+		}
+	}
+	return Member{}, false
+}
+
+// Host returns the libp2p host ID of the node of the member
+func (m Member) Host() string {
+	if m.HostPeerID != "" {
+		return m.HostPeerID
+	}
+	return m.PeerID
+}
+
+// IsMember returns true if the node ID is listed in the committee with the public key
+func (c Committee) IsMember(peerID string, publicKey string) bool {
+	m, ok := c.Member(peerID)
+	return ok && m.PublicKey == publicKey
+}
+
+// Size returns the number of members of the committee
+func (c Committee) Size() int {
+	return len(c.Members)
+}
@@ -7,3 +7,69 @@ var ErrNoPriceMessage = errors.New("no price message found")
 
 // ErrFailedToFetchPrice is returned when the price cannot be fetched from the data api.
 var ErrFailedToFetchPrice = errors.New("failed to fetch price")
+
+// ErrInvalidPrice is returned when a price is not a positive fixed-point decimal with the decimals of its feed.
+var ErrInvalidPrice = errors.New("invalid price")
+
+// ErrUnsupportedFeed is returned when a price source is not configured for the feed.
+var ErrUnsupportedFeed = errors.New("unsupported feed")
+
+// ErrInvalidSignature is returned when a signature does not verify against the public key of its signer.
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// ErrDuplicateSignature is returned when a signer or a signature appears more than once in a message.
+var ErrDuplicateSignature = errors.New("duplicate signature")
+
+// ErrUnattributableSignature is returned when a signature cannot be attributed to the signer it is listed under.
+var ErrUnattributableSignature = errors.New("unattributable signature")
+
+// ErrSigningRefused is returned when the signer refuses to sign a report because of its policy.
+var ErrSigningRefused = errors.New("signing refused")
+
+// ErrNotCommitteeMember is returned when a message is published by a node that is not listed in the committee.
+var ErrNotCommitteeMember = errors.New("not a committee member")
+
+// ErrKeyRevoked is returned when a key is listed in the revocation list.
+var ErrKeyRevoked = errors.New("key revoked")
+
+// ErrInvalidRotation is returned when a key rotation announcement is not signed by both keys or conflicts with a known rotation.
+var ErrInvalidRotation = errors.New("invalid key rotation")
+
+// ErrInvalidObservation is returned when an observation does not belong to the report or can not be attributed to its observer.
+var ErrInvalidObservation = errors.New("invalid observation")
+
+// ErrInsufficientObservations is returned when a report is built from fewer observations than the quorum.
+var ErrInsufficientObservations = errors.New("insufficient observations")
+
+// ErrNotLeader is returned when a report is proposed by a node that does not lead its epoch.
+var ErrNotLeader = errors.New("not the epoch leader")
+
+// ErrEarlyProposal is returned when a report is proposed before its epoch started.
+var ErrEarlyProposal = errors.New("proposal before the epoch started")
+
+// ErrPriceDeviation is returned when the price of a report deviates from the price the node observed by more than the tolerance.
+var ErrPriceDeviation = errors.New("price deviates from own observation")
+
+// ErrConflictingReport is returned when two copies of a report with the same message ID do not sign the same report.
+var ErrConflictingReport = errors.New("conflicting copies of a report")
+
+// ErrReportFinalized is returned when a copy of a report arrives after the report reached the quorum.
+var ErrReportFinalized = errors.New("report already finalized")
+
+// ErrReportExpired is returned when a report did not reach the quorum before its pending report expired.
+var ErrReportExpired = errors.New("report expired")
+
+// ErrInsufficientSignatures is returned when a report did not collect the signatures of the quorum.
+var ErrInsufficientSignatures = errors.New("insufficient signatures")
+
+// ErrEquivocation is returned when a node is asked to sign a second report for the same epoch of a round.
+var ErrEquivocation = errors.New("another report was signed for the epoch")
+
+// ErrCommitmentMismatch is returned when a revealed observation does not match the commitment of its observer.
+var ErrCommitmentMismatch = errors.New("observation does not match commitment")
+
+// ErrInvalidJob is returned when a job spec or the pipeline of a job is invalid.
+var ErrInvalidJob = errors.New("invalid job")
+
+// ErrWrongFeed is returned when a message of a feed is published on the topic of another feed.
+var ErrWrongFeed = errors.New("message of another feed")
@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// Evidence records that a node refused to co-sign a report because its price disagrees with the price the node observed
+// Reporter is the node ID of the node that refused to co-sign
+// Publisher is the node ID of the node that proposed the report
+// ProposedPrice is the price of the report
+// ObservedPrice is the price the reporter observed for the round
+// Deviation is the relative difference between both prices, in percent
+// Report is the signed report, so the evidence can be verified against the publisher key
+// CreatedAt is the timestamp when the report was refused
+type Evidence struct {
+	Reporter      string       `json:"reporter"`
+	Publisher     string       `json:"publisher"`
+	Feed          string       `json:"feed"`
+	Round         int64        `json:"round"`
+	Epoch         int          `json:"epoch"`
+	MessageID     string       `json:"message_id"`
+	ProposedPrice Price        `json:"proposed_price"`
+	ObservedPrice Price        `json:"observed_price"`
+	Deviation     float64      `json:"deviation"`
+	Report        PriceMessage `json:"report"`
+	CreatedAt     int64        `json:"timestamp"`
+}
+
+// Deviation returns the relative difference of price from reference, in percent
+// The difference is computed exactly, whatever the decimals of both prices, and only rounded to a float64 at the end
+func Deviation(price Price, reference Price) (float64, error) {
+	deviation, err := exactDeviation(price, reference)
+	if err != nil {
+		return 0, err
+	}
+	percent, _ := deviation.Float64()
+	return percent, nil
+}
+
+// exactDeviation returns the relative difference of price from reference, in percent, without rounding
+func exactDeviation(price Price, reference Price) (*big.Rat, error) {
+	if price.IsZero() {
+		return nil, fmt.Errorf("%w: no price", ErrInvalidPrice)
+	}
+	if reference.Sign() <= 0 {
+		return nil, fmt.Errorf("%w: reference price %q", ErrInvalidPrice, reference.String())
+	}
+	r := reference.Rat()
+	deviation := new(big.Rat).Sub(price.Rat(), r)
+	return deviation.Abs(deviation).Quo(deviation, r).Mul(deviation, big.NewRat(100, 1)), nil
+}
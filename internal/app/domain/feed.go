@@ -0,0 +1,15 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseFeed returns the base and quote assets of the feed, e.g. ETH and USD for ETH/USD
+func ParseFeed(feed string) (base string, quote string, err error) {
+	base, quote, ok := strings.Cut(feed, "/")
+	if !ok || base == "" || quote == "" || strings.Contains(quote, "/") {
+		return "", "", fmt.Errorf("%w: %q is not of the form BASE/QUOTE", ErrUnsupportedFeed, feed)
+	}
+	return base, quote, nil
+}
@@ -0,0 +1,191 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Types of the tasks of a job pipeline
+const (
+	TaskHTTP      = "http"      // Fetches a JSON document
+	TaskJSONParse = "jsonparse" // Extracts a price from a JSON document
+	TaskMultiply  = "multiply"  // Multiplies a price by a decimal
+	TaskMedian    = "median"    // Takes the median of prices
+	TaskSign      = "sign"      // Signs the observation of the node at a price
+	TaskGossip    = "gossip"    // Publishes a signed observation
+)
+
+// taskTypes are the known task types
+var taskTypes = map[string]struct{}{
+	TaskHTTP: {}, TaskJSONParse: {}, TaskMultiply: {}, TaskMedian: {}, TaskSign: {}, TaskGossip: {},
+}
+
+// JobSpec is an oracle job, declared in a job file instead of the node configuration
+// Name identifies the job in logs and task runs
+// Feed is the feed the job observes, e.g. ETH/USD
+// Topic is the GossipSub topic the reports of the feed are announced on
+// Schedule is the interval between two rounds of the feed
+// WriteQuorum is the signatures required to write a report of the feed
+// Decimals are the decimals of the prices of the feed, required as 0 is a valid value
+// DeviationThreshold and Heartbeat are the write triggers of the feed, as for the configured feeds
+// Timeout bounds every run of the pipeline, the price source timeout of the node if 0, and is shorter than the schedule
+// Tasks are the pipeline observing the price of every round: a DAG of tasks,
+// ending with a gossip task that publishes the observation signed by a sign task
+type JobSpec struct {
+	Name               string        `yaml:"name"`
+	Feed               string        `yaml:"feed"`
+	Topic              string        `yaml:"topic"`
+	Schedule           time.Duration `yaml:"schedule"`
+	WriteQuorum        string        `yaml:"write_quorum"`
+	Decimals           *uint8        `yaml:"decimals"`
+	DeviationThreshold string        `yaml:"deviation_threshold"`
+	Heartbeat          time.Duration `yaml:"heartbeat"`
+	Timeout            time.Duration `yaml:"timeout"`
+	Tasks              []TaskSpec    `yaml:"tasks"`
+}
+
+// TaskSpec is a task of the pipeline of a job
+// ID identifies the task in the pipeline
+// Type is the type of the task, e.g. http or median
+// Inputs are the IDs of the tasks whose outputs the task takes, in order
+// Params are the other keys of the task, specific to its type
+type TaskSpec struct {
+	ID     string                 `yaml:"id"`
+	Type   string                 `yaml:"type"`
+	Inputs []string               `yaml:"inputs"`
+	Params map[string]interface{} `yaml:",inline"`
+}
+
+// TaskRun records a run of a task of a job, for debugging
+// Round is the round the task ran for, 0 when the node fetched a reference price outside of its rounds
+// Inputs and Output are the JSON encoded values the task took and returned
+// Error is the error the task failed with, empty if it succeeded
+type TaskRun struct {
+	Job        string          `json:"job"`
+	Feed       string          `json:"feed"`
+	Round      int64           `json:"round"`
+	TaskID     string          `json:"task_id"`
+	TaskType   string          `json:"task_type"`
+	Inputs     json.RawMessage `json:"inputs"`
+	Output     json.RawMessage `json:"output"`
+	Error      string          `json:"error,omitempty"`
+	StartedAt  time.Time       `json:"started_at"`
+	FinishedAt time.Time       `json:"finished_at"`
+}
+
+// Validate checks the job and the shape of its pipeline
+// The parameters of the tasks are checked when the tasks are created
+func (j JobSpec) Validate() error {
+	if j.Name == "" {
+		return fmt.Errorf("%w: job has no name", ErrInvalidJob)
+	}
+	if _, _, err := ParseFeed(j.Feed); err != nil {
+		return fmt.Errorf("%w: job %s: %v", ErrInvalidJob, j.Name, err)
+	}
+	if j.Topic == "" || j.Schedule <= 0 || j.WriteQuorum == "" {
+		return fmt.Errorf("%w: job %s needs a topic, a schedule and a write quorum", ErrInvalidJob, j.Name)
+	}
+	if j.Timeout < 0 || j.Timeout >= j.Schedule {
+		return fmt.Errorf("%w: job %s has timeout %s, it must be shorter than the schedule", ErrInvalidJob, j.Name, j.Timeout)
+	}
+	if j.Decimals == nil {
+		return fmt.Errorf("%w: job %s has no decimals", ErrInvalidJob, j.Name)
+	}
+	if *j.Decimals > MaxPriceDecimals {
+		return fmt.Errorf("%w: job %s has %d decimals, at most %d", ErrInvalidJob, j.Name, *j.Decimals, MaxPriceDecimals)
+	}
+
+	tasks, err := SortTasks(j.Tasks)
+	if err != nil {
+		return fmt.Errorf("job %s: %w", j.Name, err)
+	}
+	// Every task but the last one feeds another task, so the pipeline has a single output
+	consumed := make(map[string]struct{}, len(tasks))
+	signs := 0
+	for _, task := range tasks {
+		if _, ok := taskTypes[task.Type]; !ok {
+			return fmt.Errorf("%w: job %s: task %s has unknown type %q", ErrInvalidJob, j.Name, task.ID, task.Type)
+		}
+		if task.Type == TaskSign {
+			signs++
+		}
+		for _, input := range task.Inputs {
+			consumed[input] = struct{}{}
+		}
+	}
+	for _, task := range tasks[:len(tasks)-1] {
+		if _, ok := consumed[task.ID]; !ok {
+			return fmt.Errorf("%w: job %s: the output of task %s is not used", ErrInvalidJob, j.Name, task.ID)
+		}
+	}
+
+	gossip := tasks[len(tasks)-1]
+	if gossip.Type != TaskGossip || signs != 1 {
+		return fmt.Errorf("%w: job %s: the pipeline must end with a gossip task, and have a single sign task", ErrInvalidJob, j.Name)
+	}
+	if len(gossip.Inputs) != 1 || j.task(gossip.Inputs[0]).Type != TaskSign {
+		return fmt.Errorf("%w: job %s: gossip task %s must take the output of the sign task", ErrInvalidJob, j.Name, gossip.ID)
+	}
+	return nil
+}
+
+// task returns the task of the pipeline with the ID
+func (j JobSpec) task(id string) TaskSpec {
+	for _, task := range j.Tasks {
+		if task.ID == id {
+			return task
+		}
+	}
+	return TaskSpec{}
+}
+
+// SortTasks returns the tasks sorted so every task comes after its inputs, keeping the declaration order otherwise
+// Returns an error if task IDs are not unique, an input is unknown, or the tasks are not a DAG
+func SortTasks(tasks []TaskSpec) ([]TaskSpec, error) {
+	if len(tasks) == 0 {
+		return nil, fmt.Errorf("%w: no tasks", ErrInvalidJob)
+	}
+	index := make(map[string]int, len(tasks))
+	for i, task := range tasks {
+		if task.ID == "" {
+			return nil, fmt.Errorf("%w: task %d has no id", ErrInvalidJob, i)
+		}
+		if _, ok := index[task.ID]; ok {
+			return nil, fmt.Errorf("%w: task %s is declared twice", ErrInvalidJob, task.ID)
+		}
+		index[task.ID] = i
+	}
+	for _, task := range tasks {
+		for _, input := range task.Inputs {
+			if _, ok := index[input]; !ok {
+				return nil, fmt.Errorf("%w: task %s takes unknown task %s", ErrInvalidJob, task.ID, input)
+			}
+		}
+	}
+
+	// Repeatedly take the first task whose inputs are all sorted, a cycle leaves tasks that are never ready
+	sorted := make([]TaskSpec, 0, len(tasks))
+	done := make(map[string]bool, len(tasks))
+	for len(sorted) < len(tasks) {
+		progress := false
+		for _, task := range tasks {
+			if done[task.ID] {
+				continue
+			}
+			ready := true
+			for _, input := range task.Inputs {
+				ready = ready && done[input]
+			}
+			if ready {
+				sorted = append(sorted, task)
+				done[task.ID] = true
+				progress = true
+			}
+		}
+		if !progress {
+			return nil, fmt.Errorf("%w: the tasks have a cycle", ErrInvalidJob)
+		}
+	}
+	return sorted, nil
+}
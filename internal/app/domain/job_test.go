@@ -0,0 +1,145 @@
+package domain
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testJob returns a valid job, fetching two prices and taking their median
+func testJob() JobSpec {
+	decimals := uint8(8)
+	return JobSpec{
+		Name:        "link-usd",
+		Feed:        "LINK/USD",
+		Topic:       "oracle/link-usd",
+		Schedule:    30 * time.Second,
+		WriteQuorum: "3",
+		Decimals:    &decimals,
+		Tasks: []TaskSpec{
+			{ID: "a", Type: TaskHTTP},
+			{ID: "b", Type: TaskHTTP},
+			{ID: "median", Type: TaskMedian, Inputs: []string{"a", "b"}},
+			{ID: "sign", Type: TaskSign, Inputs: []string{"median"}},
+			{ID: "gossip", Type: TaskGossip, Inputs: []string{"sign"}},
+		},
+	}
+}
+
+// taskIDs returns the IDs of the tasks, in order
+func taskIDs(tasks []TaskSpec) []string {
+	ids := make([]string, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+	return ids
+}
+
+func TestSortTasks(t *testing.T) {
+	tests := []struct {
+		name  string
+		tasks []TaskSpec
+		want  []string
+	}{
+		{
+			name:  "sorted",
+			tasks: testJob().Tasks,
+			want:  []string{"a", "b", "median", "sign", "gossip"},
+		},
+		{
+			name: "inputs declared after",
+			tasks: []TaskSpec{
+				{ID: "gossip", Inputs: []string{"sign"}},
+				{ID: "sign", Inputs: []string{"median"}},
+				{ID: "median", Inputs: []string{"b", "a"}},
+				{ID: "a"},
+				{ID: "b"},
+			},
+			want: []string{"a", "b", "median", "sign", "gossip"},
+		},
+		{
+			name: "independent tasks keep their order",
+			tasks: []TaskSpec{
+				{ID: "c", Inputs: []string{"b"}},
+				{ID: "b"},
+				{ID: "a"},
+			},
+			want: []string{"b", "a", "c"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sorted, err := SortTasks(tt.tasks)
+			if err != nil {
+				t.Fatalf("SortTasks: %v", err)
+			}
+			if got := taskIDs(sorted); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SortTasks = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSortTasksRejectsInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		tasks []TaskSpec
+	}{
+		{name: "no tasks"},
+		{name: "no id", tasks: []TaskSpec{{ID: "a"}, {}}},
+		{name: "duplicate id", tasks: []TaskSpec{{ID: "a"}, {ID: "a"}}},
+		{name: "unknown input", tasks: []TaskSpec{{ID: "a", Inputs: []string{"b"}}}},
+		{name: "self input", tasks: []TaskSpec{{ID: "a", Inputs: []string{"a"}}}},
+		{name: "cycle", tasks: []TaskSpec{{ID: "a"}, {ID: "b", Inputs: []string{"a", "c"}}, {ID: "c", Inputs: []string{"b"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := SortTasks(tt.tasks); !errors.Is(err, ErrInvalidJob) {
+				t.Errorf("SortTasks = %v, want %v", err, ErrInvalidJob)
+			}
+		})
+	}
+}
+
+func TestJobSpecValidate(t *testing.T) {
+	if err := testJob().Validate(); err != nil {
+		t.Fatalf("Validate of a valid job: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(j *JobSpec)
+	}{
+		{name: "no name", modify: func(j *JobSpec) { j.Name = "" }},
+		{name: "invalid feed", modify: func(j *JobSpec) { j.Feed = "LINKUSD" }},
+		{name: "no topic", modify: func(j *JobSpec) { j.Topic = "" }},
+		{name: "no schedule", modify: func(j *JobSpec) { j.Schedule = 0 }},
+		{name: "no write quorum", modify: func(j *JobSpec) { j.WriteQuorum = "" }},
+		{name: "negative timeout", modify: func(j *JobSpec) { j.Timeout = -time.Second }},
+		{name: "timeout of the schedule", modify: func(j *JobSpec) { j.Timeout = j.Schedule }},
+		{name: "no decimals", modify: func(j *JobSpec) { j.Decimals = nil }},
+		{name: "too many decimals", modify: func(j *JobSpec) { decimals := uint8(MaxPriceDecimals + 1); j.Decimals = &decimals }},
+		{name: "unknown task type", modify: func(j *JobSpec) { j.Tasks[0].Type = "ftp" }},
+		{name: "cycle", modify: func(j *JobSpec) { j.Tasks[0].Inputs = []string{"median"} }},
+		{name: "unused output", modify: func(j *JobSpec) { j.Tasks[2].Inputs = []string{"a"} }},
+		{name: "no gossip task", modify: func(j *JobSpec) { j.Tasks = j.Tasks[:4] }},
+		{name: "gossip of another task", modify: func(j *JobSpec) {
+			j.Tasks[4].Inputs = []string{"double"}
+			j.Tasks = append(j.Tasks[:4], TaskSpec{ID: "double", Type: TaskMultiply, Inputs: []string{"sign"}}, j.Tasks[4])
+		}},
+		{name: "two sign tasks", modify: func(j *JobSpec) {
+			j.Tasks[3].ID = "sign_median"
+			j.Tasks = append(j.Tasks[:4], TaskSpec{ID: "sign", Type: TaskSign, Inputs: []string{"sign_median"}}, j.Tasks[4])
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := testJob()
+			tt.modify(&job)
+			if err := job.Validate(); !errors.Is(err, ErrInvalidJob) {
+				t.Errorf("Validate = %v, want %v", err, ErrInvalidJob)
+			}
+		})
+	}
+}
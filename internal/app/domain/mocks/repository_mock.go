@@ -7,48 +7,47 @@ package mocks
 import (
 	domain "chainlink-lite/internal/app/domain"
 	reflect "reflect"
-	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	context "golang.org/x/net/context"
 )
 
-// MockEthPriceTicker is a mock of EthPriceTicker interface.
-type MockEthPriceTicker struct {
+// MockPriceSource is a mock of PriceSource interface.
+type MockPriceSource struct {
 	ctrl     *gomock.Controller
-	recorder *MockEthPriceTickerMockRecorder
+	recorder *MockPriceSourceMockRecorder
 }
 
-// MockEthPriceTickerMockRecorder is the mock recorder for MockEthPriceTicker.
-type MockEthPriceTickerMockRecorder struct {
-	mock *MockEthPriceTicker
+// MockPriceSourceMockRecorder is the mock recorder for MockPriceSource.
+type MockPriceSourceMockRecorder struct {
+	mock *MockPriceSource
 }
 
-// NewMockEthPriceTicker creates a new mock instance.
-func NewMockEthPriceTicker(ctrl *gomock.Controller) *MockEthPriceTicker {
-	mock := &MockEthPriceTicker{ctrl: ctrl}
-	mock.recorder = &MockEthPriceTickerMockRecorder{mock}
+// NewMockPriceSource creates a new mock instance.
+func NewMockPriceSource(ctrl *gomock.Controller) *MockPriceSource {
+	mock := &MockPriceSource{ctrl: ctrl}
+	mock.recorder = &MockPriceSourceMockRecorder{mock}
 	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockEthPriceTicker) EXPECT() *MockEthPriceTickerMockRecorder {
+func (m *MockPriceSource) EXPECT() *MockPriceSourceMockRecorder {
 	return m.recorder
 }
 
 // FetchPrice mocks base method.
-func (m *MockEthPriceTicker) FetchPrice() (string, error) {
+func (m *MockPriceSource) FetchPrice(ctx context.Context, feed string) (domain.Price, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FetchPrice")
-	ret0, _ := ret[0].(string)
+	ret := m.ctrl.Call(m, "FetchPrice", ctx, feed)
+	ret0, _ := ret[0].(domain.Price)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // FetchPrice indicates an expected call of FetchPrice.
-func (mr *MockEthPriceTickerMockRecorder) FetchPrice() *gomock.Call {
+func (mr *MockPriceSourceMockRecorder) FetchPrice(ctx, feed interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchPrice", reflect.TypeOf((*MockEthPriceTicker)(nil).FetchPrice))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchPrice", reflect.TypeOf((*MockPriceSource)(nil).FetchPrice), ctx, feed)
 }
 
 // MockPriceMessageRepository is a mock of PriceMessageRepository interface.
@@ -74,17 +73,83 @@ func (m *MockPriceMessageRepository) EXPECT() *MockPriceMessageRepositoryMockRec
 	return m.recorder
 }
 
-// StorePriceIfAllowed mocks base method.
-func (m *MockPriceMessageRepository) StorePriceIfAllowed(ctx context.Context, priceMsg *domain.PriceMessage, minInterval time.Duration) (bool, error) {
+// IsEvaluated mocks base method.
+func (m *MockPriceMessageRepository) IsEvaluated(ctx context.Context, feed string, round int64) (bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "StorePriceIfAllowed", ctx, priceMsg, minInterval)
+	ret := m.ctrl.Call(m, "IsEvaluated", ctx, feed, round)
 	ret0, _ := ret[0].(bool)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// StorePriceIfAllowed indicates an expected call of StorePriceIfAllowed.
-func (mr *MockPriceMessageRepositoryMockRecorder) StorePriceIfAllowed(ctx, priceMsg, minInterval interface{}) *gomock.Call {
+// IsEvaluated indicates an expected call of IsEvaluated.
+func (mr *MockPriceMessageRepositoryMockRecorder) IsEvaluated(ctx, feed, round interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StorePriceIfAllowed", reflect.TypeOf((*MockPriceMessageRepository)(nil).StorePriceIfAllowed), ctx, priceMsg, minInterval)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsEvaluated", reflect.TypeOf((*MockPriceMessageRepository)(nil).IsEvaluated), ctx, feed, round)
+}
+
+// StoreEvidence mocks base method.
+func (m *MockPriceMessageRepository) StoreEvidence(ctx context.Context, evidence *domain.Evidence) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StoreEvidence", ctx, evidence)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StoreEvidence indicates an expected call of StoreEvidence.
+func (mr *MockPriceMessageRepositoryMockRecorder) StoreEvidence(ctx, evidence interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreEvidence", reflect.TypeOf((*MockPriceMessageRepository)(nil).StoreEvidence), ctx, evidence)
+}
+
+// StorePriceIfTriggered mocks base method.
+func (m *MockPriceMessageRepository) StorePriceIfTriggered(ctx context.Context, priceMsg *domain.PriceMessage, policy domain.WritePolicy) (domain.WriteTrigger, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StorePriceIfTriggered", ctx, priceMsg, policy)
+	ret0, _ := ret[0].(domain.WriteTrigger)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StorePriceIfTriggered indicates an expected call of StorePriceIfTriggered.
+func (mr *MockPriceMessageRepositoryMockRecorder) StorePriceIfTriggered(ctx, priceMsg, policy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StorePriceIfTriggered", reflect.TypeOf((*MockPriceMessageRepository)(nil).StorePriceIfTriggered), ctx, priceMsg, policy)
+}
+
+// MockTaskRunRepository is a mock of TaskRunRepository interface.
+type MockTaskRunRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockTaskRunRepositoryMockRecorder
+}
+
+// MockTaskRunRepositoryMockRecorder is the mock recorder for MockTaskRunRepository.
+type MockTaskRunRepositoryMockRecorder struct {
+	mock *MockTaskRunRepository
+}
+
+// NewMockTaskRunRepository creates a new mock instance.
+func NewMockTaskRunRepository(ctrl *gomock.Controller) *MockTaskRunRepository {
+	mock := &MockTaskRunRepository{ctrl: ctrl}
+	mock.recorder = &MockTaskRunRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTaskRunRepository) EXPECT() *MockTaskRunRepositoryMockRecorder {
+	return m.recorder
+}
+
+// StoreTaskRuns mocks base method.
+func (m *MockTaskRunRepository) StoreTaskRuns(ctx context.Context, runs []domain.TaskRun) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StoreTaskRuns", ctx, runs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StoreTaskRuns indicates an expected call of StoreTaskRuns.
+func (mr *MockTaskRunRepositoryMockRecorder) StoreTaskRuns(ctx, runs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreTaskRuns", reflect.TypeOf((*MockTaskRunRepository)(nil).StoreTaskRuns), ctx, runs)
 }
@@ -1,30 +1,124 @@
 package domain
 
 import (
+	"encoding/hex"
 	"fmt"
+	"math/bits"
 )
 
 // PriceMessage represents the message that will be published to the pubsub topic
 // MessageID is the unique identifier of the message
-// Price is the price of the cryptocurrency
-// Publisher is the node ID of the original publisher
+// Feed is the name of the price feed, e.g. ETH/USD
+// Round is the aggregation round the report was built for
+// Epoch is the leader term of the round the report was proposed in
+// Price is the aggregated answer, the median of the observations, with the decimals of the feed
+// Observations are the signed observations of the round the answer was aggregated from, sorted by observer
+// Publisher is the node ID the original publisher signs as
 // Writer is the node ID of node that persisted the message
+// Quorum is the number of signatures that was required when the message was persisted
 // Signers are the node IDs of the nodes that signed the message
+// PublicKeys are the hex encoded public keys of the signers, in the same order as Signers
 // Signatures are the signatures of the message
+// Aggregate replaces Signers, PublicKeys and Signatures when the committee aggregates its signatures with BLS
 // CreatedAt is the timestamp when the message was originaly created
 // Timestamp is the timestamp when the message was persisted
 type PriceMessage struct {
-	MessageID  string   `json:"message_id" validate:"required"`
-	Price      string   `json:"price" validate:"required,numeric"`
-	Publisher  string   `json:"publisher" validate:"required"`
-	Writer     string   `json:"-"`
-	Signers    []string `json:"signers" validate:"required,min=1"`
-	Signatures []string `json:"signatures" validate:"required,min=1"`
-	CreatedAt  int64    `json:"timestamp" validate:"required"`
-	Timestamp  int64    `json:"-"`
+	MessageID    string              `json:"message_id" validate:"required"`
+	Feed         string              `json:"feed" validate:"required"`
+	Round        int64               `json:"round" validate:"required"`
+	Epoch        int                 `json:"epoch" validate:"gte=0"`
+	Price        Price               `json:"price"`
+	Observations []Observation       `json:"observations" validate:"required,dive"`
+	Publisher    string              `json:"publisher" validate:"required"`
+	Writer       string              `json:"-"`
+	Quorum       int                 `json:"-"`
+	Signers      []string            `json:"signers,omitempty" validate:"required_without=Aggregate"`
+	PublicKeys   []string            `json:"public_keys,omitempty" validate:"required_without=Aggregate"`
+	Signatures   []string            `json:"signatures,omitempty" validate:"required_without=Aggregate"`
+	Aggregate    *AggregateSignature `json:"aggregate,omitempty"`
+	CreatedAt    int64               `json:"timestamp" validate:"required"`
+	Timestamp    int64               `json:"-"`
+}
+
+// AggregateSignature is a BLS12-381 signature aggregated over the committee members flagged in the bitmap
+// CommitteeVersion is the version of the committee the bitmap indexes into
+// Bitmap is the hex encoded bitmap of signers, bit i of byte i/8 set for the i-th member of the committee
+// Signature is the hex encoded aggregate signature
+type AggregateSignature struct {
+	CommitteeVersion int    `json:"committee_version" validate:"required"`
+	Bitmap           string `json:"bitmap" validate:"required,hexadecimal"`
+	Signature        string `json:"signature" validate:"required,hexadecimal"`
 }
 
 func (p PriceMessage) String() string {
-	return fmt.Sprintf("{MessageID: %s, Price: %s, Publisher: %s, Writer: %s, Signers: %v, Signatures: %v, CreatedAt: %d, Timestamp: %d}",
-		p.MessageID, p.Price, p.Publisher, p.Writer, p.Signers, p.Signatures, p.CreatedAt, p.Timestamp)
+	if p.Aggregate != nil {
+		return fmt.Sprintf("{MessageID: %s, Feed: %s, Round: %d, Epoch: %d, Price: %s, Observations: %d, Publisher: %s, Writer: %s, Aggregate: %+v, CreatedAt: %d, Timestamp: %d}",
+			p.MessageID, p.Feed, p.Round, p.Epoch, p.Price, len(p.Observations), p.Publisher, p.Writer, *p.Aggregate, p.CreatedAt, p.Timestamp)
+	}
+	return fmt.Sprintf("{MessageID: %s, Feed: %s, Round: %d, Epoch: %d, Price: %s, Observations: %d, Publisher: %s, Writer: %s, Signers: %v, PublicKeys: %v, Signatures: %v, CreatedAt: %d, Timestamp: %d}",
+		p.MessageID, p.Feed, p.Round, p.Epoch, p.Price, len(p.Observations), p.Publisher, p.Writer, p.Signers, p.PublicKeys, p.Signatures, p.CreatedAt, p.Timestamp)
+}
+
+// SignatureCount returns the number of signers of the message
+func (p PriceMessage) SignatureCount() int {
+	if p.Aggregate != nil {
+		bitmap, err := hex.DecodeString(p.Aggregate.Bitmap)
+		if err != nil {
+			return 0
+		}
+		count := 0
+		for _, b := range bitmap {
+			count += bits.OnesCount8(b)
+		}
+		return count
+	}
+	return len(p.Signatures)
+}
+
+// SignerIndex returns the position of the signer in Signers, or -1 if it has not signed the message
+func (p PriceMessage) SignerIndex(signer string) int {
+	for i, s := range p.Signers {
+		if s == signer {
+			return i
+		}
+	}
+	return -1
+}
+
+// CollapseDuplicateSigners keeps only the first entry of every signer, so a node can never be counted twice
+// Returns the number of entries that were removed
+func (p *PriceMessage) CollapseDuplicateSigners() int {
+	seen := make(map[string]struct{}, len(p.Signers))
+	return p.RetainSigners(func(signer string, _ string) bool {
+		if _, ok := seen[signer]; ok {
+			return false
+		}
+		seen[signer] = struct{}{}
+		return true
+	})
+}
+
+// RetainSigners removes the signatures of the signers for which keep returns false
+// Returns the number of entries that were removed
+func (p *PriceMessage) RetainSigners(keep func(signer string, publicKey string) bool) int {
+	if len(p.Signatures) != len(p.Signers) || len(p.PublicKeys) != len(p.Signers) {
+		// Leave malformed messages untouched, they are rejected by the signature verification
+		return 0
+	}
+
+	signers := p.Signers[:0]
+	publicKeys := p.PublicKeys[:0]
+	signatures := p.Signatures[:0]
+	for i, signer := range p.Signers {
+		if !keep(signer, p.PublicKeys[i]) {
+			continue
+		}
+		signers = append(signers, signer)
+		publicKeys = append(publicKeys, p.PublicKeys[i])
+		signatures = append(signatures, p.Signatures[i])
+	}
+
+	removed := len(p.Signers) - len(signers)
+	p.Signers, p.PublicKeys, p.Signatures = signers, publicKeys, signatures
+	return removed
 }
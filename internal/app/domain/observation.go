@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+)
+
+// observationDomain separates observation signatures from any other use of the node keys
+const observationDomain = "chainlink-lite/observation"
+
+// Observation is the price a node observed for a round, signed by the node
+// Round identifies the aggregation round the observation contributes to
+// Feed is the name of the price feed, e.g. ETH/USD
+// Price is the price observed by the node, with the decimals of the feed
+// Observer is the node ID of the observing node
+// PublicKey is the hex encoded public key of the observer
+// Signature is the signature of the observer, tagged with its signature scheme
+// CreatedAt is the timestamp when the price was observed
+// Salt is the hex encoded random salt hiding the price in the commitment of the observer, when rounds commit before they reveal
+type Observation struct {
+	Round     int64  `json:"round" validate:"required"`
+	Feed      string `json:"feed" validate:"required"`
+	Price     Price  `json:"price"`
+	Observer  string `json:"observer" validate:"required"`
+	PublicKey string `json:"public_key" validate:"required"`
+	Signature string `json:"signature" validate:"required"`
+	CreatedAt int64  `json:"timestamp" validate:"required"`
+	Salt      string `json:"salt,omitempty" validate:"omitempty,hexadecimal"`
+}
+
+// SigningPayload returns the canonical encoding of the observation that the observer signs
+func (o Observation) SigningPayload() []byte {
+	buf := make([]byte, 0, 128)
+	buf = append(buf, observationDomain...)
+	buf = append(buf, 0)
+	buf = binary.BigEndian.AppendUint16(buf, SigningPayloadVersion)
+	buf = binary.BigEndian.AppendUint64(buf, uint64(o.Round))
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(o.Feed)))
+	buf = append(buf, o.Feed...)
+	buf = o.Price.AppendBinary(buf)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(o.Observer)))
+	buf = append(buf, o.Observer...)
+	buf = binary.BigEndian.AppendUint64(buf, uint64(o.CreatedAt))
+	// The salt is only encoded when set, so observations of rounds without commitments sign the same payload
+	if o.Salt != "" {
+		buf = binary.BigEndian.AppendUint32(buf, uint32(len(o.Salt)))
+		buf = append(buf, o.Salt...)
+	}
+	return buf
+}
+
+// SortObservations sorts the observations by observer, the canonical order of the observations of a report
+func SortObservations(observations []Observation) {
+	sort.Slice(observations, func(i, j int) bool {
+		return observations[i].Observer < observations[j].Observer
+	})
+}
+
+// Median returns the median price of the observations
+// As in OCR, it is the observation at index n/2 of the observations sorted by price,
+// so the answer is always a price that was observed and no rounding is involved
+func Median(observations []Observation) (Price, error) {
+	if len(observations) == 0 {
+		return Price{}, fmt.Errorf("%w: no observations", ErrInsufficientObservations)
+	}
+	for _, o := range observations {
+		if o.Price.IsZero() {
+			return Price{}, fmt.Errorf("%w: no price of %s", ErrInvalidObservation, o.Observer)
+		}
+	}
+
+	// Order the observations by observer, so ties are broken the same way on every node
+	sorted := make([]Observation, len(observations))
+	copy(sorted, observations)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Observer < sorted[j].Observer })
+	prices := make([]Price, len(sorted))
+	for i, o := range sorted {
+		prices[i] = o.Price
+	}
+	return prices[MedianIndex(prices)], nil
+}
+
+// MedianIndex returns the index in prices of their median, the price at index n/2 of the prices sorted
+// Equal prices may have other decimals, they keep their order in prices so every node picks the same one
+// The prices must not be empty
+func MedianIndex(prices []Price) int {
+	indexes := make([]int, len(prices))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.SliceStable(indexes, func(i, j int) bool { return prices[indexes[i]].Cmp(prices[indexes[j]]) < 0 })
+	return indexes[len(indexes)/2]
+}
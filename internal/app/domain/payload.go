@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"encoding/binary"
+)
+
+// SigningPayloadVersion is the version of the canonical encoding produced by SigningPayload
+const SigningPayloadVersion uint16 = 4
+
+// signingDomain separates price report signatures from any other use of the node keys
+const signingDomain = "chainlink-lite/price-report"
+
+// SigningPayload returns the canonical encoding of the report that every signer signs.
+// It is the domain tag and the payload version followed by the length prefixed message ID and feed,
+// the binary encoding of the price, the length prefixed publisher, the creation time, the round and epoch,
+// and the observations. Two distinct reports never share an encoding, so a signature can not be replayed
+// on another message.
+func (p PriceMessage) SigningPayload() []byte {
+	buf := make([]byte, 0, 128)
+	buf = append(buf, signingDomain...)
+	buf = append(buf, 0)
+	buf = binary.BigEndian.AppendUint16(buf, SigningPayloadVersion)
+	for _, field := range []string{p.MessageID, p.Feed} {
+		buf = binary.BigEndian.AppendUint32(buf, uint32(len(field)))
+		buf = append(buf, field...)
+	}
+	buf = p.Price.AppendBinary(buf)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(p.Publisher)))
+	buf = append(buf, p.Publisher...)
+	buf = binary.BigEndian.AppendUint64(buf, uint64(p.CreatedAt))
+	buf = binary.BigEndian.AppendUint64(buf, uint64(p.Round))
+	buf = binary.BigEndian.AppendUint32(buf, uint32(p.Epoch))
+	return append(buf, p.ObservationsPayload()...)
+}
+
+// ObservationsPayload returns the canonical encoding of the observations the answer was aggregated from:
+// their number followed by the length prefixed observer and the binary encoding of the price of every observation.
+// Observations are signed by their observers, so the report only needs to commit to who observed what.
+func (p PriceMessage) ObservationsPayload() []byte {
+	buf := make([]byte, 0, 4+len(p.Observations)*64)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(p.Observations)))
+	for _, o := range p.Observations {
+		buf = binary.BigEndian.AppendUint32(buf, uint32(len(o.Observer)))
+		buf = append(buf, o.Observer...)
+		buf = o.Price.AppendBinary(buf)
+	}
+	return buf
+}
@@ -0,0 +1,264 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// MaxPriceDecimals is the largest number of decimals of a price, the decimals of an amount of ether in wei
+const MaxPriceDecimals = 18
+
+// priceBinarySize is the size of the binary encoding of a price: its decimals and its 32 byte answer
+const priceBinarySize = 1 + 32
+
+// maxAnswer bounds the answer of a price to the non-negative int256 values
+var maxAnswer = new(big.Int).Lsh(big.NewInt(1), 255)
+
+// Price is a fixed-point decimal price, the integer answer scaled by 10^-decimals,
+// as the int256 answer and the decimals of an EVM aggregator, e.g. 345678000000 with 8 decimals for 3456.78
+// Prices are never negative, and the zero value is the absence of a price
+type Price struct {
+	answer   *big.Int
+	decimals uint8
+}
+
+// NewPrice returns the price of the answer with the decimals
+func NewPrice(answer *big.Int, decimals uint8) (Price, error) {
+	if answer == nil || answer.Sign() < 0 || answer.Cmp(maxAnswer) >= 0 {
+		return Price{}, fmt.Errorf("%w: answer %v is not a non-negative int256", ErrInvalidPrice, answer)
+	}
+	if decimals > MaxPriceDecimals {
+		return Price{}, fmt.Errorf("%w: %d decimals, at most %d", ErrInvalidPrice, decimals, MaxPriceDecimals)
+	}
+	return Price{answer: new(big.Int).Set(answer), decimals: decimals}, nil
+}
+
+// ParsePrice parses the canonical string of a price, as returned by String
+// The decimals of the price are the number of digits after the point, and no other form of the price is accepted,
+// so the string of a price is unique and can be compared and hashed as is
+func ParsePrice(s string) (Price, error) {
+	integer, fraction, point := strings.Cut(s, ".")
+	if !isDigits(integer) || (len(integer) > 1 && integer[0] == '0') || (point && !isDigits(fraction)) {
+		return Price{}, fmt.Errorf("%w: %q is not a canonical decimal", ErrInvalidPrice, s)
+	}
+	if len(fraction) > MaxPriceDecimals {
+		return Price{}, fmt.Errorf("%w: %q has more than %d decimals", ErrInvalidPrice, s, MaxPriceDecimals)
+	}
+	answer, _ := new(big.Int).SetString(integer+fraction, 10)
+	return NewPrice(answer, uint8(len(fraction)))
+}
+
+// ParseDecimal parses a non-negative decimal in any notation returned by a price API, e.g. 3456.780, 0.5 or 1.2e-5
+// The price is exact, with the decimals written, or as many decimals as it needs in exponent notation,
+// and is rounded half up to MaxPriceDecimals when it needs more, as the float of a sub-cent asset may
+func ParseDecimal(s string) (Price, error) {
+	value, ok := new(big.Rat).SetString(s)
+	if !ok || strings.Contains(s, "/") || value.Sign() < 0 {
+		return Price{}, fmt.Errorf("%w: %q is not a non-negative decimal", ErrInvalidPrice, s)
+	}
+	decimals := 0
+	if !strings.ContainsAny(s, "eE") {
+		_, fraction, _ := strings.Cut(s, ".")
+		decimals = min(len(fraction), MaxPriceDecimals)
+	}
+	scaled := new(big.Rat).Mul(value, new(big.Rat).SetInt(pow10(uint8(decimals))))
+	for ; !scaled.IsInt() && decimals < MaxPriceDecimals; decimals++ {
+		scaled.Mul(scaled, big.NewRat(10, 1))
+	}
+	answer, remainder := new(big.Int).QuoRem(scaled.Num(), scaled.Denom(), new(big.Int))
+	if remainder.Lsh(remainder, 1).Cmp(scaled.Denom()) >= 0 {
+		answer.Add(answer, big.NewInt(1))
+	}
+	return NewPrice(answer, uint8(decimals))
+}
+
+// isDigits reports whether s is a non-empty string of decimal digits
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// IsZero reports whether p is the zero value, which is no price at all, unlike a price of 0
+func (p Price) IsZero() bool {
+	return p.answer == nil
+}
+
+// Answer returns the integer answer of the price, the price scaled by 10^decimals
+func (p Price) Answer() *big.Int {
+	if p.answer == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Set(p.answer)
+}
+
+// Decimals returns the number of decimals of the price
+func (p Price) Decimals() uint8 {
+	return p.decimals
+}
+
+// Sign returns 1 if the price is positive, and 0 if it is 0 or absent
+func (p Price) Sign() int {
+	if p.answer == nil {
+		return 0
+	}
+	return p.answer.Sign()
+}
+
+// Round returns the price with the decimals, rounding half up when decimals are dropped
+func (p Price) Round(decimals uint8) Price {
+	if p.answer == nil {
+		return Price{}
+	}
+	if decimals >= p.decimals {
+		return Price{answer: new(big.Int).Mul(p.answer, pow10(decimals-p.decimals)), decimals: decimals}
+	}
+	divisor := pow10(p.decimals - decimals)
+	answer, remainder := new(big.Int).QuoRem(p.answer, divisor, new(big.Int))
+	if remainder.Lsh(remainder, 1).Cmp(divisor) >= 0 {
+		answer.Add(answer, big.NewInt(1))
+	}
+	return Price{answer: answer, decimals: decimals}
+}
+
+// Mul returns the exact product of the prices, rounded half up to MaxPriceDecimals when it has more decimals
+func (p Price) Mul(q Price) (Price, error) {
+	if p.IsZero() || q.IsZero() {
+		return Price{}, fmt.Errorf("%w: no price", ErrInvalidPrice)
+	}
+	product := Price{answer: new(big.Int).Mul(p.answer, q.answer), decimals: p.decimals + q.decimals}
+	if product.decimals > MaxPriceDecimals {
+		product = product.Round(MaxPriceDecimals)
+	}
+	return NewPrice(product.answer, product.decimals)
+}
+
+// Cmp compares the prices exactly, whatever their decimals, and returns -1, 0 or 1
+// An absent price compares as 0
+func (p Price) Cmp(q Price) int {
+	decimals := p.decimals
+	if q.decimals > decimals {
+		decimals = q.decimals
+	}
+	return p.Round(decimals).Answer().Cmp(q.Round(decimals).Answer())
+}
+
+// Equal reports whether the prices have the same answer and decimals, and so the same encoding
+func (p Price) Equal(q Price) bool {
+	return p.decimals == q.decimals && p.Answer().Cmp(q.Answer()) == 0 && p.IsZero() == q.IsZero()
+}
+
+// Rat returns the exact value of the price
+func (p Price) Rat() *big.Rat {
+	return new(big.Rat).SetFrac(p.Answer(), pow10(p.decimals))
+}
+
+// Float64 returns the nearest float64 value of the price, for display and floating point policies only
+func (p Price) Float64() float64 {
+	value, _ := p.Rat().Float64()
+	return value
+}
+
+// String returns the canonical string of the price: its integer part, and exactly its decimals after a point
+// The string of an absent price is empty
+func (p Price) String() string {
+	if p.answer == nil {
+		return ""
+	}
+	digits := p.answer.String()
+	decimals := int(p.decimals)
+	if decimals == 0 {
+		return digits
+	}
+	if len(digits) <= decimals {
+		digits = strings.Repeat("0", decimals-len(digits)+1) + digits
+	}
+	return digits[:len(digits)-decimals] + "." + digits[len(digits)-decimals:]
+}
+
+// AppendBinary appends the binary encoding of the price to buf: its decimals, followed by its answer
+// as a 32 byte big endian int256, as an EVM aggregator encodes it
+func (p Price) AppendBinary(buf []byte) []byte {
+	buf = append(buf, p.decimals)
+	return append(buf, p.Answer().FillBytes(make([]byte, 32))...)
+}
+
+// MarshalBinary returns the binary encoding of the price
+func (p Price) MarshalBinary() ([]byte, error) {
+	if p.answer == nil {
+		return nil, fmt.Errorf("%w: no price", ErrInvalidPrice)
+	}
+	return p.AppendBinary(make([]byte, 0, priceBinarySize)), nil
+}
+
+// UnmarshalBinary decodes the binary encoding of a price
+func (p *Price) UnmarshalBinary(data []byte) error {
+	if len(data) != priceBinarySize {
+		return fmt.Errorf("%w: %d bytes, expected %d", ErrInvalidPrice, len(data), priceBinarySize)
+	}
+	price, err := NewPrice(new(big.Int).SetBytes(data[1:]), data[0])
+	if err != nil {
+		return err
+	}
+	*p = price
+	return nil
+}
+
+// MarshalJSON encodes the price as its canonical string, or null if there is no price
+func (p Price) MarshalJSON() ([]byte, error) {
+	if p.answer == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal(p.String())
+}
+
+// UnmarshalJSON decodes the canonical string of a price, JSON numbers are refused as their precision is lost by most decoders
+func (p *Price) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*p = Price{}
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("%w: %s is not a string", ErrInvalidPrice, data)
+	}
+	price, err := ParsePrice(s)
+	if err != nil {
+		return err
+	}
+	*p = price
+	return nil
+}
+
+// Value stores the price as its canonical string, so a NUMERIC column keeps its decimals
+func (p Price) Value() (driver.Value, error) {
+	if p.answer == nil {
+		return nil, nil
+	}
+	return p.String(), nil
+}
+
+// Check checks that the price is a positive price with the decimals of its feed
+func (p Price) Check(decimals uint8) error {
+	if p.Sign() <= 0 {
+		return fmt.Errorf("%w: %q is not positive", ErrInvalidPrice, p.String())
+	}
+	if p.decimals != decimals {
+		return fmt.Errorf("%w: %s has %d decimals, the feed has %d", ErrInvalidPrice, p, p.decimals, decimals)
+	}
+	return nil
+}
+
+// pow10 returns 10^n
+func pow10(n uint8) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
@@ -0,0 +1,275 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+// mustParse returns the price of the canonical string, failing the test if it is not one
+func mustParse(t *testing.T, s string) Price {
+	t.Helper()
+	price, err := ParsePrice(s)
+	if err != nil {
+		t.Fatalf("ParsePrice(%q): %v", s, err)
+	}
+	return price
+}
+
+func TestParsePrice(t *testing.T) {
+	tests := []struct {
+		in       string
+		answer   string
+		decimals uint8
+	}{
+		{in: "0", answer: "0", decimals: 0},
+		{in: "3456", answer: "3456", decimals: 0},
+		{in: "3456.78", answer: "345678", decimals: 2},
+		{in: "3456.780", answer: "3456780", decimals: 3},
+		{in: "0.00000001", answer: "1", decimals: 8},
+		{in: "1.000000000000000001", answer: "1000000000000000001", decimals: 18},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			price := mustParse(t, tt.in)
+			if price.Answer().String() != tt.answer || price.Decimals() != tt.decimals {
+				t.Errorf("ParsePrice = %s with %d decimals, want %s with %d", price.Answer(), price.Decimals(), tt.answer, tt.decimals)
+			}
+			if price.String() != tt.in {
+				t.Errorf("String = %s, want %s", price, tt.in)
+			}
+		})
+	}
+}
+
+func TestParsePriceRejectsNonCanonical(t *testing.T) {
+	for _, in := range []string{"", "01.5", "00", "1.", ".5", "-1", "+1", "1e5", "1.5e-3", " 1", "1,5", "0x10", "1.0000000000000000001"} {
+		t.Run(in, func(t *testing.T) {
+			if _, err := ParsePrice(in); !errors.Is(err, ErrInvalidPrice) {
+				t.Errorf("ParsePrice(%q) = %v, want %v", in, err, ErrInvalidPrice)
+			}
+		})
+	}
+}
+
+func TestNewPriceBounds(t *testing.T) {
+	largest := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(1))
+	if _, err := NewPrice(largest, 0); err != nil {
+		t.Errorf("NewPrice(2^255-1): %v", err)
+	}
+	tests := []struct {
+		name     string
+		answer   *big.Int
+		decimals uint8
+	}{
+		{name: "2^255", answer: new(big.Int).Lsh(big.NewInt(1), 255), decimals: 0},
+		{name: "negative", answer: big.NewInt(-1), decimals: 0},
+		{name: "nil", answer: nil, decimals: 0},
+		{name: "too many decimals", answer: big.NewInt(1), decimals: MaxPriceDecimals + 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewPrice(tt.answer, tt.decimals); !errors.Is(err, ErrInvalidPrice) {
+				t.Errorf("NewPrice = %v, want %v", err, ErrInvalidPrice)
+			}
+		})
+	}
+	// The canonical string of 2^255 is refused as well
+	if _, err := ParsePrice(new(big.Int).Lsh(big.NewInt(1), 255).String()); !errors.Is(err, ErrInvalidPrice) {
+		t.Errorf("ParsePrice(2^255) = %v, want %v", err, ErrInvalidPrice)
+	}
+}
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "3456.780", want: "3456.780"},
+		{in: "0.5", want: "0.5"},
+		{in: "42", want: "42"},
+		{in: "1.2e-5", want: "0.000012"},
+		{in: "1.5E2", want: "150"},
+		{in: "3.45678e3", want: "3456.78"},
+		// Decimals past the maximum are rounded half up
+		{in: "1.2345678901234567e-05", want: "0.000012345678901235"},
+		{in: "0.1234567890123456784", want: "0.123456789012345678"},
+		{in: "0.1234567890123456785", want: "0.123456789012345679"},
+		{in: "5e-19", want: "0.000000000000000001"},
+		{in: "4e-19", want: "0.000000000000000000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			price, err := ParseDecimal(tt.in)
+			if err != nil {
+				t.Fatalf("ParseDecimal: %v", err)
+			}
+			if price.String() != tt.want {
+				t.Errorf("ParseDecimal = %s, want %s", price, tt.want)
+			}
+		})
+	}
+	for _, in := range []string{"", "-1", "1/2", "abc", "NaN"} {
+		if _, err := ParseDecimal(in); !errors.Is(err, ErrInvalidPrice) {
+			t.Errorf("ParseDecimal(%q) = %v, want %v", in, err, ErrInvalidPrice)
+		}
+	}
+}
+
+func TestRound(t *testing.T) {
+	tests := []struct {
+		in       string
+		decimals uint8
+		want     string
+	}{
+		{in: "3456.785", decimals: 2, want: "3456.79"},
+		{in: "3456.784", decimals: 2, want: "3456.78"},
+		{in: "3456.795", decimals: 2, want: "3456.80"},
+		{in: "0.5", decimals: 0, want: "1"},
+		{in: "0.49", decimals: 0, want: "0"},
+		{in: "3456.78", decimals: 8, want: "3456.78000000"},
+		{in: "3456.78", decimals: 2, want: "3456.78"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := mustParse(t, tt.in).Round(tt.decimals); got.String() != tt.want {
+				t.Errorf("Round(%d) = %s, want %s", tt.decimals, got, tt.want)
+			}
+		})
+	}
+	if got := (Price{}).Round(8); !got.IsZero() {
+		t.Errorf("Round of no price = %s, want no price", got)
+	}
+}
+
+func TestCmp(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{a: "3456.78", b: "3456.78000000", want: 0},
+		{a: "3456.781", b: "3456.78", want: 1},
+		{a: "3456.7", b: "3456.78", want: -1},
+		{a: "0.000000000000000001", b: "0", want: 1},
+		{a: "1", b: "0.999999999999999999", want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+" "+tt.b, func(t *testing.T) {
+			a, b := mustParse(t, tt.a), mustParse(t, tt.b)
+			if got := a.Cmp(b); got != tt.want {
+				t.Errorf("Cmp = %d, want %d", got, tt.want)
+			}
+			if got := b.Cmp(a); got != -tt.want {
+				t.Errorf("reverse Cmp = %d, want %d", got, -tt.want)
+			}
+		})
+	}
+	// Equal prices with other decimals compare equal, but do not have the same encoding
+	if a, b := mustParse(t, "1.5"), mustParse(t, "1.50"); a.Equal(b) {
+		t.Errorf("%s and %s are Equal", a, b)
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		answer   int64
+		decimals uint8
+		want     string
+	}{
+		{answer: 345678000000, decimals: 8, want: "3456.78000000"},
+		{answer: 1, decimals: 8, want: "0.00000001"},
+		{answer: 12, decimals: 2, want: "0.12"},
+		{answer: 0, decimals: 3, want: "0.000"},
+		{answer: 100, decimals: 0, want: "100"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			price, err := NewPrice(big.NewInt(tt.answer), tt.decimals)
+			if err != nil {
+				t.Fatalf("NewPrice: %v", err)
+			}
+			if price.String() != tt.want {
+				t.Errorf("String = %s, want %s", price, tt.want)
+			}
+		})
+	}
+	if got := (Price{}).String(); got != "" {
+		t.Errorf("String of no price = %q, want empty", got)
+	}
+}
+
+func TestAppendBinary(t *testing.T) {
+	price := mustParse(t, "3456.78")
+	want := append([]byte{2}, make([]byte, 29)...)
+	want = append(want, 0x05, 0x46, 0x4e) // 345678
+	if got := price.AppendBinary([]byte{0xff}); !bytes.Equal(got, append([]byte{0xff}, want...)) {
+		t.Errorf("AppendBinary = %x, want ff%x", got, want)
+	}
+
+	// The largest answer fills the 32 bytes, with the sign bit of the int256 clear
+	largest, err := NewPrice(new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(1)), 18)
+	if err != nil {
+		t.Fatalf("NewPrice: %v", err)
+	}
+	data, err := largest.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary: %v", err)
+	}
+	if len(data) != priceBinarySize || data[0] != 18 || data[1] != 0x7f || data[32] != 0xff {
+		t.Errorf("MarshalBinary = %x", data)
+	}
+
+	var decoded Price
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary: %v", err)
+	}
+	if !decoded.Equal(largest) {
+		t.Errorf("UnmarshalBinary = %s, want %s", decoded, largest)
+	}
+	// An answer with the sign bit set is not a non-negative int256
+	data[1] = 0x80
+	if err := decoded.UnmarshalBinary(data); !errors.Is(err, ErrInvalidPrice) {
+		t.Errorf("UnmarshalBinary of a negative int256 = %v, want %v", err, ErrInvalidPrice)
+	}
+	if _, err := (Price{}).MarshalBinary(); !errors.Is(err, ErrInvalidPrice) {
+		t.Errorf("MarshalBinary of no price = %v, want %v", err, ErrInvalidPrice)
+	}
+}
+
+func TestPriceJSON(t *testing.T) {
+	for _, s := range []string{"3456.78", "0.00000001", "100", "1.000000000000000001"} {
+		t.Run(s, func(t *testing.T) {
+			price := mustParse(t, s)
+			data, err := json.Marshal(price)
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+			if string(data) != `"`+s+`"` {
+				t.Errorf("Marshal = %s", data)
+			}
+			var decoded Price
+			if err := json.Unmarshal(data, &decoded); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			if !decoded.Equal(price) {
+				t.Errorf("round trip = %s, want %s", decoded, price)
+			}
+		})
+	}
+
+	var absent Price
+	if err := json.Unmarshal([]byte("null"), &absent); err != nil || !absent.IsZero() {
+		t.Errorf("Unmarshal(null) = %s, %v, want no price", absent, err)
+	}
+	if data, err := json.Marshal(Price{}); err != nil || string(data) != "null" {
+		t.Errorf("Marshal of no price = %s, %v, want null", data, err)
+	}
+	for _, in := range []string{`3456.78`, `"01.5"`, `"1."`, `"1e5"`, `"-1"`, `true`} {
+		var price Price
+		if err := json.Unmarshal([]byte(in), &price); !errors.Is(err, ErrInvalidPrice) {
+			t.Errorf("Unmarshal(%s) = %v, want %v", in, err, ErrInvalidPrice)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Quorum is the number of signatures a report needs to be written, relative to the size of the committee
+// With n members, a committee tolerates f = (n-1)/3 faulty members. The quorum is one of
+// "f+1", at least one honest signer, "2f+1", a majority of honest signers, "<p>%", a percentage of the members rounded up,
+// or "<k>", an absolute number of signatures whatever the size of the committee
+type Quorum struct {
+	spec       string
+	percentage float64
+	absolute   int
+}
+
+// ParseQuorum parses the quorum
+func ParseQuorum(spec string) (Quorum, error) {
+	spec = strings.ReplaceAll(strings.TrimSpace(spec), " ", "")
+	switch {
+	case spec == "f+1" || spec == "2f+1":
+		return Quorum{spec: spec}, nil
+	case strings.HasSuffix(spec, "%"):
+		p, err := strconv.ParseFloat(strings.TrimSuffix(spec, "%"), 64)
+		if err != nil || p <= 0 || p > 100 {
+			return Quorum{}, fmt.Errorf("invalid quorum percentage %q", spec)
+		}
+		return Quorum{spec: spec, percentage: p}, nil
+	default:
+		k, err := strconv.Atoi(spec)
+		if err != nil || k <= 0 {
+			return Quorum{}, fmt.Errorf("invalid quorum %q, expected f+1, 2f+1, a percentage or a positive number", spec)
+		}
+		return Quorum{spec: spec, absolute: k}, nil
+	}
+}
+
+// Relative returns true if the quorum depends on the size of the committee
+func (q Quorum) Relative() bool {
+	return q.absolute == 0
+}
+
+// Threshold returns the number of signatures required with a committee of size members
+func (q Quorum) Threshold(size int) int {
+	if !q.Relative() {
+		return q.absolute
+	}
+	f := (size - 1) / 3
+	if f < 0 {
+		f = 0
+	}
+	switch q.spec {
+	case "f+1":
+		return f + 1
+	case "2f+1":
+		return 2*f + 1
+	}
+	threshold := int(math.Ceil(float64(size) * q.percentage / 100))
+	if threshold < 1 {
+		threshold = 1
+	}
+	return threshold
+}
+
+func (q Quorum) String() string {
+	return q.spec
+}
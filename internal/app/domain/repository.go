@@ -1,19 +1,31 @@
 package domain
 
 import (
-	"time"
-
 	"golang.org/x/net/context"
 )
 
-type EthPriceTicker interface {
-	// Fetch the price of Ethereum in USD
-	FetchPrice() (string, error)
+type PriceSource interface {
+	// Fetch the price of the feed, e.g. the price of ETH in USD for ETH/USD
+	// The price is exact, with the decimals the source returned it with
+	FetchPrice(ctx context.Context, feed string) (Price, error)
 }
 
 type PriceMessageRepository interface {
-	// Store the priceMsg if at least minInterval has passed since the last write
+	// Store the priceMsg if a trigger of the policy fires, against the last answer written for the feed
 	// Timestamp is used to check the last write time
-	// Returns true if the message was stored, false if it was skipped
-	StorePriceIfAllowed(ctx context.Context, priceMsg *PriceMessage, minInterval time.Duration) (bool, error)
+	// The round is recorded as evaluated whether the message is stored or skipped
+	// Returns the trigger that fired, or TriggerNone if the message was skipped
+	StorePriceIfTriggered(ctx context.Context, priceMsg *PriceMessage, policy WritePolicy) (WriteTrigger, error)
+
+	// Check if the write triggers were already evaluated for the round of the feed, whether its report was written or skipped
+	IsEvaluated(ctx context.Context, feed string, round int64) (bool, error)
+
+	// Store the evidence against the publisher of a report the node refused to co-sign
+	// Evidence of the same report by the same node is only stored once
+	StoreEvidence(ctx context.Context, evidence *Evidence) error
+}
+
+type TaskRunRepository interface {
+	// Store the runs of the tasks of a job, for debugging
+	StoreTaskRuns(ctx context.Context, runs []TaskRun) error
 }
@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"encoding/binary"
+)
+
+// rotationDomain separates key rotation signatures from any other use of the node keys
+const rotationDomain = "chainlink-lite/key-rotation"
+
+// KeyRotation announces that a node replaces its key with a successor
+// OldPeerID and OldPublicKey are the identity being retired
+// NewPeerID and NewPublicKey are the successor identity
+// EffectiveAt is the unix time of the rotation, the old key is accepted for the overlap window after it
+// OldSignature and NewSignature are the hex encoded signatures of both keys over the announcement
+type KeyRotation struct {
+	OldPeerID    string `json:"old_peer_id" validate:"required"`
+	OldPublicKey string `json:"old_public_key" validate:"required"`
+	NewPeerID    string `json:"new_peer_id" validate:"required,nefield=OldPeerID"`
+	NewPublicKey string `json:"new_public_key" validate:"required"`
+	EffectiveAt  int64  `json:"effective_at" validate:"required"`
+	OldSignature string `json:"old_signature" validate:"required"`
+	NewSignature string `json:"new_signature" validate:"required"`
+}
+
+// SigningPayload returns the canonical encoding of the announcement signed by both keys
+func (r KeyRotation) SigningPayload() []byte {
+	buf := make([]byte, 0, 256)
+	buf = append(buf, rotationDomain...)
+	buf = append(buf, 0)
+	for _, field := range []string{r.OldPeerID, r.OldPublicKey, r.NewPeerID, r.NewPublicKey} {
+		buf = binary.BigEndian.AppendUint32(buf, uint32(len(field)))
+		buf = append(buf, field...)
+	}
+	buf = binary.BigEndian.AppendUint64(buf, uint64(r.EffectiveAt))
+	return buf
+}
+
+// RevocationList lists the node keys whose signatures must no longer be counted
+type RevocationList struct {
+	Revoked []RevokedKey `yaml:"revoked"`
+}
+
+// RevokedKey is a revoked node key
+// PeerID is the node ID derived from the key
+// Reason is an optional human-readable reason
+type RevokedKey struct {
+	PeerID string `yaml:"peer_id"`
+	Reason string `yaml:"reason"`
+}
+
+// IsRevoked returns true if the node ID is listed as revoked
+func (l RevocationList) IsRevoked(peerID string) bool {
+	for _, r := range l.Revoked {
+		if r.PeerID == peerID {
+			return true
+		}
+	}
+	return false
+}
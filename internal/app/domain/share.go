@@ -0,0 +1,22 @@
+package domain
+
+// SignatureShare is the signature of a node over a report, returned to the proposer of the report
+// Signer, PublicKey and Signature are set for an individual signature, tagged with its signature scheme
+// Aggregate is set instead when the committee aggregates its signatures with BLS, flagging the node only
+type SignatureShare struct {
+	Signer    string              `json:"signer,omitempty"`
+	PublicKey string              `json:"public_key,omitempty"`
+	Signature string              `json:"signature,omitempty"`
+	Aggregate *AggregateSignature `json:"aggregate,omitempty"`
+}
+
+// SignRequest asks a node to co-sign the report proposed by the leader of its epoch
+type SignRequest struct {
+	Report PriceMessage `json:"report"`
+}
+
+// SignResponse is the answer to a SignRequest, either the signature share of the node or the reason it refused to sign
+type SignResponse struct {
+	Share *SignatureShare `json:"share,omitempty"`
+	Error string          `json:"error,omitempty"`
+}
@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SignatureScheme identifies the key type and the digest a signature was produced with
+type SignatureScheme string
+
+const (
+	// SchemeECDSA is an ECDSA P-256 signature over the canonical payload
+	SchemeECDSA SignatureScheme = "ecdsa"
+	// SchemeEd25519 is an ed25519 signature over the canonical payload
+	SchemeEd25519 SignatureScheme = "ed25519"
+	// SchemeSecp256k1EIP191 is a recoverable secp256k1 signature over the EIP-191 digest of the canonical payload
+	SchemeSecp256k1EIP191 SignatureScheme = "secp256k1-eip191"
+	// SchemeSecp256k1EIP712 is a recoverable secp256k1 signature over the EIP-712 digest of the report
+	SchemeSecp256k1EIP712 SignatureScheme = "secp256k1-eip712"
+)
+
+// FormatSignature tags the hex encoded signature with its scheme, as carried in PriceMessage.Signatures
+func FormatSignature(scheme SignatureScheme, hexSignature string) string {
+	return string(scheme) + ":" + hexSignature
+}
+
+// ParseSignature splits a tagged signature into its scheme and its hex encoded signature
+func ParseSignature(signature string) (SignatureScheme, string, error) {
+	scheme, hexSignature, ok := strings.Cut(signature, ":")
+	if !ok || scheme == "" || hexSignature == "" {
+		return "", "", fmt.Errorf("%w: signature is not tagged with its scheme", ErrInvalidSignature)
+	}
+	return SignatureScheme(scheme), hexSignature, nil
+}
@@ -0,0 +1,21 @@
+package domain
+
+import (
+	"golang.org/x/net/context"
+)
+
+type Signer interface {
+	// ID returns the node ID the signatures are attributed to
+	ID() string
+	// PublicKey returns the hex encoded public key that verifies the signatures
+	PublicKey() string
+	// Sign the canonical payload of the report
+	// Returns the hex encoded signature
+	SignReport(ctx context.Context, report *PriceMessage) (string, error)
+	// Sign the canonical payload of the observation
+	// Returns the hex encoded signature
+	SignObservation(ctx context.Context, observation *Observation) (string, error)
+	// Sign the canonical payload of the commitment to an observation
+	// Returns the hex encoded signature
+	SignCommitment(ctx context.Context, commitment *Commitment) (string, error)
+}
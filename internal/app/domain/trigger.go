@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"time"
+)
+
+// WriteTrigger is the reason an answer was written to the database
+type WriteTrigger string
+
+const (
+	// TriggerNone means that no trigger fired and the answer is not written
+	TriggerNone WriteTrigger = ""
+	// TriggerInitial fires for the first answer of a feed
+	TriggerInitial WriteTrigger = "initial"
+	// TriggerDeviation fires when the answer deviates from the last written answer by more than the threshold
+	TriggerDeviation WriteTrigger = "deviation"
+	// TriggerHeartbeat fires when no answer was written for the heartbeat interval
+	TriggerHeartbeat WriteTrigger = "heartbeat"
+	// TriggerInterval fires for every answer when the feed has neither a deviation threshold nor a heartbeat
+	TriggerInterval WriteTrigger = "interval"
+)
+
+// WritePolicy decides when a new answer of a feed is written, as Chainlink data feeds do
+// MinInterval is the minimum time between two writes, whatever the trigger
+// DeviationThreshold is the relative change from the last written answer that triggers a write, in percent, 0 disables it
+// It is a decimal compared exactly with the deviation, so an answer exactly at the threshold never triggers a write
+// Heartbeat is the maximum time without a write, 0 disables it
+type WritePolicy struct {
+	MinInterval        time.Duration
+	DeviationThreshold Price
+	Heartbeat          time.Duration
+}
+
+// Trigger returns the trigger that fires for the answer, given the last written answer of the feed and the time it was written
+func (p WritePolicy) Trigger(price Price, lastPrice Price, lastWrite time.Time, now time.Time) WriteTrigger {
+	if lastPrice.IsZero() {
+		return TriggerInitial
+	}
+	elapsed := now.Sub(lastWrite)
+	if elapsed < p.MinInterval {
+		return TriggerNone
+	}
+	if p.DeviationThreshold.Sign() <= 0 && p.Heartbeat <= 0 {
+		return TriggerInterval
+	}
+
+	if p.DeviationThreshold.Sign() > 0 {
+		if deviation, err := exactDeviation(price, lastPrice); err == nil && deviation.Cmp(p.DeviationThreshold.Rat()) > 0 {
+			return TriggerDeviation
+		}
+	}
+	if p.Heartbeat > 0 && elapsed >= p.Heartbeat {
+		return TriggerHeartbeat
+	}
+	return TriggerNone
+}
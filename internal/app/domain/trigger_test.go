@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWritePolicyTrigger(t *testing.T) {
+	now := time.Now()
+	policy := WritePolicy{
+		MinInterval:        time.Minute,
+		DeviationThreshold: mustParse(t, "0.5"),
+		Heartbeat:          time.Hour,
+	}
+	tests := []struct {
+		name      string
+		policy    WritePolicy
+		price     string
+		lastPrice string
+		elapsed   time.Duration
+		want      WriteTrigger
+	}{
+		{name: "first answer", policy: policy, price: "100", elapsed: 0, want: TriggerInitial},
+		{name: "within the min interval", policy: policy, price: "200", lastPrice: "100", elapsed: time.Second, want: TriggerNone},
+		{name: "above the threshold", policy: policy, price: "100.51", lastPrice: "100", elapsed: 2 * time.Minute, want: TriggerDeviation},
+		{name: "below the threshold", policy: policy, price: "99.51", lastPrice: "100", elapsed: 2 * time.Minute, want: TriggerNone},
+		// 0.5% of 3456.78 is exactly 17.2839, where a float64 deviation may round either way
+		{name: "exactly at the threshold", policy: policy, price: "3474.0639", lastPrice: "3456.78", elapsed: 2 * time.Minute, want: TriggerNone},
+		{name: "just above the threshold", policy: policy, price: "3474.06390001", lastPrice: "3456.78", elapsed: 2 * time.Minute, want: TriggerDeviation},
+		{name: "heartbeat", policy: policy, price: "100", lastPrice: "100", elapsed: time.Hour, want: TriggerHeartbeat},
+		{name: "no trigger", policy: WritePolicy{MinInterval: time.Minute}, price: "100", lastPrice: "100", elapsed: 2 * time.Minute, want: TriggerInterval},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var lastPrice Price
+			if tt.lastPrice != "" {
+				lastPrice = mustParse(t, tt.lastPrice)
+			}
+			if got := tt.policy.Trigger(mustParse(t, tt.price), lastPrice, now.Add(-tt.elapsed), now); got != tt.want {
+				t.Errorf("Trigger = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,247 @@
+package service
+
+import (
+	"chainlink-lite/internal/app/domain"
+	"encoding/hex"
+	"fmt"
+	"math/bits"
+
+	"github.com/cloudflare/circl/ecc/bls12381"
+	"github.com/cloudflare/circl/sign/bls"
+	"github.com/libp2p/go-libp2p/core/crypto"
+)
+
+// blsKeySalt separates the BLS key derivation from any other use of the node key
+const blsKeySalt = "chainlink-lite/bls-key"
+
+// blsPossessionDomain separates proofs of possession from report signatures of the BLS key
+const blsPossessionDomain = "chainlink-lite/bls-pop"
+
+// BLSSigner signs reports with a BLS12-381 key for aggregate signatures
+// The key is derived from the node key, so it is as persistent as the keystore
+type BLSSigner struct {
+	key       *bls.PrivateKey[bls.KeyG1SigG2]
+	publicKey string
+}
+
+// NewBLSSigner derives the BLS key of the node from its private key
+func NewBLSSigner(nodeKey crypto.PrivKey) (*BLSSigner, error) {
+	ikm, err := nodeKey.Raw()
+	if err != nil {
+		return nil, err
+	}
+	key, err := bls.KeyGen[bls.KeyG1SigG2](ikm, []byte(blsKeySalt), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, err := key.PublicKey().MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return &BLSSigner{key: key, publicKey: hex.EncodeToString(publicKey)}, nil
+}
+
+// PublicKey returns the hex encoded BLS public key, as listed in the committee file
+func (b *BLSSigner) PublicKey() string {
+	return b.publicKey
+}
+
+// ProofOfPossession returns the hex encoded signature of the BLS key over its own public key and the node ID,
+// as listed in the committee file next to the BLS public key
+func (b *BLSSigner) ProofOfPossession(peerID string) string {
+	return hex.EncodeToString(bls.Sign(b.key, possessionPayload(peerID, b.publicKey)))
+}
+
+// AddSignature signs the report as the member of the committee with the node ID,
+// and aggregates the signature into the aggregate signature of the report
+func (b *BLSSigner) AddSignature(report *domain.PriceMessage, committee *domain.Committee, signer string) error {
+	index := committee.Index(signer)
+	if index < 0 {
+		return fmt.Errorf("%w: %s", domain.ErrNotCommitteeMember, signer)
+	}
+
+	signature := bls.Sign(b.key, report.SigningPayload())
+	bitmap := make([]byte, (committee.Size()+7)/8)
+	if report.Aggregate != nil {
+		current, bitmapCurrent, err := decodeAggregate(report.Aggregate, committee)
+		if err != nil {
+			return err
+		}
+		if bitmapCurrent[index/8]&(1<<(index%8)) != 0 {
+			return fmt.Errorf("%w: signer %s", domain.ErrDuplicateSignature, signer)
+		}
+		signature, err = bls.Aggregate(bls.G1{}, []bls.Signature{current, signature})
+		if err != nil {
+			return err
+		}
+		bitmap = bitmapCurrent
+	}
+	bitmap[index/8] |= 1 << (index % 8)
+
+	report.Aggregate = &domain.AggregateSignature{
+		CommitteeVersion: committee.Version,
+		Bitmap:           hex.EncodeToString(bitmap),
+		Signature:        hex.EncodeToString(signature),
+	}
+	return nil
+}
+
+// VerifyAggregateSignature verifies the aggregate signature of the report against the aggregated
+// BLS public keys of the committee members flagged in its bitmap
+// The keys are aggregated as is, so the proof of possession of every key must have been checked when the committee was loaded
+// Returns the committee members that signed the report
+func VerifyAggregateSignature(report *domain.PriceMessage, committee *domain.Committee) ([]domain.Member, error) {
+	if report.Aggregate == nil {
+		return nil, fmt.Errorf("%w: report has no aggregate signature", domain.ErrUnattributableSignature)
+	}
+	signature, bitmap, err := decodeAggregate(report.Aggregate, committee)
+	if err != nil {
+		return nil, err
+	}
+
+	var signers []domain.Member
+	var aggregated bls12381.G1
+	aggregated.SetIdentity()
+	for i, m := range committee.Members {
+		if bitmap[i/8]&(1<<(i%8)) == 0 {
+			continue
+		}
+		key, err := hex.DecodeString(m.BLSPublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("%w: member %s has no valid BLS public key", domain.ErrUnattributableSignature, m.PeerID)
+		}
+		var point bls12381.G1
+		if err := point.SetBytes(key); err != nil {
+			return nil, fmt.Errorf("%w: member %s has no valid BLS public key", domain.ErrUnattributableSignature, m.PeerID)
+		}
+		aggregated.Add(&aggregated, &point)
+		signers = append(signers, m)
+	}
+	if len(signers) == 0 {
+		return nil, fmt.Errorf("%w: empty signer bitmap", domain.ErrUnattributableSignature)
+	}
+
+	var pub bls.PublicKey[bls.KeyG1SigG2]
+	if err := pub.UnmarshalBinary(aggregated.BytesCompressed()); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidSignature, err)
+	}
+	if !bls.Verify(&pub, report.SigningPayload(), signature) {
+		return nil, fmt.Errorf("%w: aggregate signature", domain.ErrInvalidSignature)
+	}
+	return signers, nil
+}
+
+// MergeAggregateSignatures combines two verified aggregate signatures of the same report
+// Signatures of disjoint sets of signers are aggregated. An aggregate can not be split again, so if the sets
+// overlap, the aggregate with the most signers is kept
+// Returns the combined aggregate signature
+func MergeAggregateSignatures(a *domain.AggregateSignature, b *domain.AggregateSignature, committee *domain.Committee) (*domain.AggregateSignature, error) {
+	signatureA, bitmapA, err := decodeAggregate(a, committee)
+	if err != nil {
+		return nil, err
+	}
+	signatureB, bitmapB, err := decodeAggregate(b, committee)
+	if err != nil {
+		return nil, err
+	}
+
+	larger := a
+	if countBits(bitmapB) > countBits(bitmapA) {
+		larger = b
+	}
+	bitmap := make([]byte, len(bitmapA))
+	for i := range bitmapA {
+		if bitmapA[i]&bitmapB[i] != 0 {
+			return larger, nil
+		}
+		bitmap[i] = bitmapA[i] | bitmapB[i]
+	}
+
+	signature, err := bls.Aggregate(bls.G1{}, []bls.Signature{signatureA, signatureB})
+	if err != nil {
+		return nil, err
+	}
+	return &domain.AggregateSignature{
+		CommitteeVersion: committee.Version,
+		Bitmap:           hex.EncodeToString(bitmap),
+		Signature:        hex.EncodeToString(signature),
+	}, nil
+}
+
+// countBits returns the number of signers flagged in the bitmap
+func countBits(bitmap []byte) int {
+	count := 0
+	for _, b := range bitmap {
+		count += bits.OnesCount8(b)
+	}
+	return count
+}
+
+// ValidateBLSPublicKey checks that the hex encoded BLS public key is a valid point
+func ValidateBLSPublicKey(publicKey string) error {
+	data, err := hex.DecodeString(publicKey)
+	if err != nil {
+		return err
+	}
+	var pub bls.PublicKey[bls.KeyG1SigG2]
+	if err := pub.UnmarshalBinary(data); err != nil {
+		return err
+	}
+	if !pub.Validate() {
+		return fmt.Errorf("invalid BLS public key")
+	}
+	return nil
+}
+
+// VerifyProofOfPossession checks that the member holds the private key of its BLS public key
+// Aggregating keys without a proof lets a member register a key derived from the keys of the others,
+// and forge the aggregate signature of the whole committee
+func VerifyProofOfPossession(peerID string, publicKey string, proof string) error {
+	data, err := hex.DecodeString(publicKey)
+	if err != nil {
+		return err
+	}
+	var pub bls.PublicKey[bls.KeyG1SigG2]
+	if err := pub.UnmarshalBinary(data); err != nil {
+		return err
+	}
+	signature, err := hex.DecodeString(proof)
+	if err != nil || !bls.Verify(&pub, possessionPayload(peerID, publicKey), signature) {
+		return fmt.Errorf("%w: proof of possession of the BLS key of %s", domain.ErrInvalidSignature, peerID)
+	}
+	return nil
+}
+
+// possessionPayload returns the payload of the proof of possession of the BLS public key of the node ID
+func possessionPayload(peerID string, publicKey string) []byte {
+	buf := make([]byte, 0, len(blsPossessionDomain)+len(peerID)+len(publicKey)+2)
+	buf = append(buf, blsPossessionDomain...)
+	buf = append(buf, 0)
+	buf = append(buf, peerID...)
+	buf = append(buf, 0)
+	return append(buf, publicKey...)
+}
+
+// decodeAggregate decodes the aggregate signature and its bitmap, checking it indexes into the committee
+func decodeAggregate(aggregate *domain.AggregateSignature, committee *domain.Committee) (bls.Signature, []byte, error) {
+	if aggregate.CommitteeVersion != committee.Version {
+		return nil, nil, fmt.Errorf("%w: aggregate over committee version %d, enforcing version %d",
+			domain.ErrUnattributableSignature, aggregate.CommitteeVersion, committee.Version)
+	}
+	bitmap, err := hex.DecodeString(aggregate.Bitmap)
+	if err != nil || len(bitmap) != (committee.Size()+7)/8 {
+		return nil, nil, fmt.Errorf("%w: invalid signer bitmap", domain.ErrUnattributableSignature)
+	}
+	// Bits past the last member would be counted as signers without a key
+	for i := committee.Size(); i < len(bitmap)*8; i++ {
+		if bitmap[i/8]&(1<<(i%8)) != 0 {
+			return nil, nil, fmt.Errorf("%w: signer bitmap flags a non-member", domain.ErrUnattributableSignature)
+		}
+	}
+	signature, err := hex.DecodeString(aggregate.Signature)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", domain.ErrInvalidSignature, err)
+	}
+	return signature, bitmap, nil
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/libp2p/go-libp2p/core/crypto"
+)
+
+func newTestBLSSigner(t *testing.T) *BLSSigner {
+	t.Helper()
+	key, _, err := crypto.GenerateEd25519Key(nil)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	signer, err := NewBLSSigner(key)
+	if err != nil {
+		t.Fatalf("NewBLSSigner: %v", err)
+	}
+	return signer
+}
+
+func TestVerifyProofOfPossession(t *testing.T) {
+	signer, other := newTestBLSSigner(t), newTestBLSSigner(t)
+	tests := []struct {
+		name      string
+		peerID    string
+		publicKey string
+		proof     string
+		valid     bool
+	}{
+		{name: "own proof", peerID: "node-a", publicKey: signer.PublicKey(), proof: signer.ProofOfPossession("node-a"), valid: true},
+		{name: "missing proof", peerID: "node-a", publicKey: signer.PublicKey(), proof: ""},
+		{name: "proof of another node", peerID: "node-a", publicKey: signer.PublicKey(), proof: signer.ProofOfPossession("node-b")},
+		{name: "proof of another key", peerID: "node-a", publicKey: signer.PublicKey(), proof: other.ProofOfPossession("node-a")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifyProofOfPossession(tt.peerID, tt.publicKey, tt.proof)
+			if (err == nil) != tt.valid {
+				t.Errorf("VerifyProofOfPossession = %v, want valid %t", err, tt.valid)
+			}
+		})
+	}
+}
@@ -39,35 +39,44 @@ func (d *DiscoveryService) FindPeers() {
 		case <-d.context.Done():
 			return
 		case <-ticker.C:
-			peers, err := d.routingDiscovery.FindPeers(d.context, d.node.Topic)
-			if err != nil {
-				log.Warnf("Failed to find peers: %s", err)
-				continue
+			for _, topic := range d.node.Topics {
+				d.findPeers(topic)
 			}
+		}
+	}
+}
 
-			for p := range peers {
-				if p.ID == d.node.Host.ID() {
-					log.Debug("Found self in discovery")
-					continue
-				}
+// findPeers connects to the peers advertising the topic
+func (d *DiscoveryService) findPeers(topic string) {
+	peers, err := d.routingDiscovery.FindPeers(d.context, topic)
+	if err != nil {
+		log.Warnf("Failed to find peers: %s", err)
+		return
+	}
 
-				if d.node.Host.Network().Connectedness(p.ID) == network.Connected {
-					log.Debugf("Already connected to peer %s", p.ID)
-					continue
-				}
+	for p := range peers {
+		if p.ID == d.node.Host.ID() {
+			log.Debug("Found self in discovery")
+			continue
+		}
 
-				err := d.node.Host.Connect(d.context, p)
-				if err != nil {
-					log.Debugf("Failed to connect to peer %s: %v", p.ID, err)
-				} else {
-					log.Infof("Connected to new peer %s", p.ID)
-				}
-			}
+		if d.node.Host.Network().Connectedness(p.ID) == network.Connected {
+			log.Debugf("Already connected to peer %s", p.ID)
+			continue
+		}
+
+		err := d.node.Host.Connect(d.context, p)
+		if err != nil {
+			log.Debugf("Failed to connect to peer %s: %v", p.ID, err)
+		} else {
+			log.Infof("Connected to new peer %s", p.ID)
 		}
 	}
 }
 
-// Advertise is a utility function that persistently advertises the service
+// Advertise is a utility function that persistently advertises the service on the topic of every feed
 func (d *DiscoveryService) Advertise() {
-	util.Advertise(d.context, d.routingDiscovery, d.node.Topic)
+	for _, topic := range d.node.Topics {
+		util.Advertise(d.context, d.routingDiscovery, topic)
+	}
 }
@@ -0,0 +1,270 @@
+package service
+
+import (
+	"chainlink-lite/internal/app/domain"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
+	log "github.com/sirupsen/logrus"
+)
+
+// KeyRegistry tracks which node keys may publish and sign reports
+// A key is accepted if it is listed in the committee, or in any case when there is no committee,
+// or if it is the successor of an accepted key. A rotated key is accepted for the overlap window
+// after the rotation, and a revoked key is never accepted.
+// The committee and the revocation list are only loaded at startup, nodes must restart to enforce a new version.
+type KeyRegistry struct {
+	committee   *domain.Committee
+	revocations domain.RevocationList
+	overlap     time.Duration
+	maxSkew     time.Duration // How far in the future a rotation may take effect
+
+	mu         sync.RWMutex
+	rotations  map[string]domain.KeyRotation // by old node ID
+	successors map[string]domain.KeyRotation // by new node ID
+}
+
+// NewKeyRegistry creates a registry enforcing the committee, if not nil, and the revocation list
+// Rotations taking effect more than maxSkew in the future are refused, as they would keep the old key accepted until then
+func NewKeyRegistry(committee *domain.Committee, revocations domain.RevocationList, overlap time.Duration, maxSkew time.Duration) *KeyRegistry {
+	return &KeyRegistry{
+		committee:   committee,
+		revocations: revocations,
+		overlap:     overlap,
+		maxSkew:     maxSkew,
+		rotations:   make(map[string]domain.KeyRotation),
+		successors:  make(map[string]domain.KeyRotation),
+	}
+}
+
+// Committee returns the committee, or nil if any node is accepted
+func (k *KeyRegistry) Committee() *domain.Committee {
+	return k.committee
+}
+
+// CommitteeSize returns the number of committee members with a key that is not revoked, or 0 if there is no committee
+// A member whose key was rotated is counted as long as its successor key is not revoked
+func (k *KeyRegistry) CommitteeSize() int {
+	if k.committee == nil {
+		return 0
+	}
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	size := 0
+	for _, m := range k.committee.Members {
+		if k.hasActiveKey(m.PeerID) {
+			size++
+		}
+	}
+	return size
+}
+
+// hasActiveKey returns true if the key, or one of its successors, is neither revoked nor retired, with the lock held
+func (k *KeyRegistry) hasActiveKey(peerID string) bool {
+	// Rotations are only accepted from known keys to unknown keys, so the chain has no cycle
+	for {
+		if !k.IsRevoked(peerID) && !k.isRetired(peerID) {
+			return true
+		}
+		r, ok := k.rotations[peerID]
+		if !ok {
+			return false
+		}
+		peerID = r.NewPeerID
+	}
+}
+
+// IsRevoked returns true if the node key is listed in the revocation list
+func (k *KeyRegistry) IsRevoked(peerID string) bool {
+	return k.revocations.IsRevoked(peerID)
+}
+
+// IsKnown returns true if the node ID may publish reports, whatever the public key it presents
+func (k *KeyRegistry) IsKnown(peerID string) bool {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	if k.IsRevoked(peerID) || k.isRetired(peerID) {
+		return false
+	}
+	if _, ok := k.successors[peerID]; ok || k.committee == nil {
+		return true
+	}
+	_, ok := k.committee.Member(peerID)
+	return ok
+}
+
+// IsKnownHost returns true if the libp2p host ID runs the node of a key that may sign reports
+// A member signing with a remote signer runs on the host of its committee entry, other nodes run on a host of their key
+func (k *KeyRegistry) IsKnownHost(hostID string) bool {
+	if k.committee != nil {
+		if m, ok := k.committee.MemberByHost(hostID); ok && m.HostPeerID != "" {
+			return k.IsKnown(m.PeerID)
+		}
+	}
+	return k.IsKnown(hostID)
+}
+
+// IsActive returns true if signatures of the node ID with the public key are counted
+func (k *KeyRegistry) IsActive(peerID string, publicKey string) bool {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.isActive(peerID, publicKey)
+}
+
+// isActive checks the key with the lock held
+func (k *KeyRegistry) isActive(peerID string, publicKey string) bool {
+	if k.IsRevoked(peerID) || k.isRetired(peerID) {
+		return false
+	}
+	if successor, ok := k.successors[peerID]; ok {
+		return successor.NewPublicKey == publicKey
+	}
+	return k.committee == nil || k.committee.IsMember(peerID, publicKey)
+}
+
+// Owner returns the node ID of the original key of a rotated identity, or the node ID itself
+// Keys of the same owner are only counted once
+func (k *KeyRegistry) Owner(peerID string) string {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	// Rotations are only accepted from known keys to unknown keys, so the chain has no cycle
+	for {
+		r, ok := k.successors[peerID]
+		if !ok {
+			return peerID
+		}
+		peerID = r.OldPeerID
+	}
+}
+
+// AddRotation verifies the rotation announcement and accepts the successor key
+// Returns true if the rotation was not known yet
+func (k *KeyRegistry) AddRotation(rotation domain.KeyRotation) (bool, error) {
+	if err := VerifyRotation(rotation); err != nil {
+		return false, err
+	}
+	if k.IsRevoked(rotation.OldPeerID) || k.IsRevoked(rotation.NewPeerID) {
+		return false, fmt.Errorf("%w: rotation from %s to %s", domain.ErrKeyRevoked, rotation.OldPeerID, rotation.NewPeerID)
+	}
+	// Rotations take effect when they are created, only allow for the clock skew of the node
+	if effectiveAt := time.Unix(rotation.EffectiveAt, 0); effectiveAt.After(time.Now().Add(k.maxSkew)) {
+		return false, fmt.Errorf("%w: rotation from %s takes effect at %s, in the future", domain.ErrInvalidRotation,
+			rotation.OldPeerID, effectiveAt.Format(time.RFC3339))
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	known, rotated := k.rotations[rotation.OldPeerID]
+	_, succeeded := k.successors[rotation.NewPeerID]
+	if rotated {
+		if known.NewPeerID == rotation.NewPeerID && known.EffectiveAt == rotation.EffectiveAt {
+			return false, nil
+		}
+		return false, fmt.Errorf("%w: %s was already rotated to %s", domain.ErrInvalidRotation, known.OldPeerID, known.NewPeerID)
+	}
+	if succeeded {
+		return false, fmt.Errorf("%w: %s is already the successor of another key", domain.ErrInvalidRotation, rotation.NewPeerID)
+	}
+
+	// Only a key that is accepted right now may hand over to a successor
+	if !k.isActive(rotation.OldPeerID, rotation.OldPublicKey) {
+		return false, fmt.Errorf("%w: %s is not an active key", domain.ErrInvalidRotation, rotation.OldPeerID)
+	}
+	if k.committee != nil {
+		if _, ok := k.committee.Member(rotation.NewPeerID); ok {
+			return false, fmt.Errorf("%w: %s is already a committee member", domain.ErrInvalidRotation, rotation.NewPeerID)
+		}
+	}
+
+	k.rotations[rotation.OldPeerID] = rotation
+	k.successors[rotation.NewPeerID] = rotation
+	log.Infof("Accepted key rotation from %s to %s, old key retired at %s", rotation.OldPeerID, rotation.NewPeerID,
+		time.Unix(rotation.EffectiveAt, 0).Add(k.overlap).Format(time.RFC3339))
+	return true, nil
+}
+
+// isRetired returns true if the key was rotated more than the overlap window ago, with the lock held
+func (k *KeyRegistry) isRetired(peerID string) bool {
+	r, ok := k.rotations[peerID]
+	return ok && time.Since(time.Unix(r.EffectiveAt, 0)) > k.overlap
+}
+
+// NewKeyRotation creates the announcement that the old key is replaced by the new key, signed by both keys
+func NewKeyRotation(oldKey crypto.PrivKey, newKey crypto.PrivKey, effectiveAt time.Time) (domain.KeyRotation, error) {
+	oldID, oldPublicKey, err := identityOf(oldKey)
+	if err != nil {
+		return domain.KeyRotation{}, err
+	}
+	newID, newPublicKey, err := identityOf(newKey)
+	if err != nil {
+		return domain.KeyRotation{}, err
+	}
+	rotation := domain.KeyRotation{
+		OldPeerID:    oldID,
+		OldPublicKey: oldPublicKey,
+		NewPeerID:    newID,
+		NewPublicKey: newPublicKey,
+		EffectiveAt:  effectiveAt.Unix(),
+	}
+
+	payload := rotation.SigningPayload()
+	oldSignature, err := oldKey.Sign(payload)
+	if err != nil {
+		return domain.KeyRotation{}, err
+	}
+	newSignature, err := newKey.Sign(payload)
+	if err != nil {
+		return domain.KeyRotation{}, err
+	}
+	rotation.OldSignature = hex.EncodeToString(oldSignature)
+	rotation.NewSignature = hex.EncodeToString(newSignature)
+	return rotation, nil
+}
+
+// VerifyRotation checks that the announcement is signed by both the old and the new key
+func VerifyRotation(rotation domain.KeyRotation) error {
+	if err := validator.New().Struct(rotation); err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrInvalidRotation, err)
+	}
+
+	payload := rotation.SigningPayload()
+	for _, k := range []struct{ id, publicKey, signature string }{
+		{rotation.OldPeerID, rotation.OldPublicKey, rotation.OldSignature},
+		{rotation.NewPeerID, rotation.NewPublicKey, rotation.NewSignature},
+	} {
+		pub, err := VerifyIdentity(k.id, k.publicKey)
+		if err != nil {
+			return fmt.Errorf("%w: %v", domain.ErrInvalidRotation, err)
+		}
+		signature, err := hex.DecodeString(k.signature)
+		if err != nil {
+			return fmt.Errorf("%w: %v", domain.ErrInvalidRotation, err)
+		}
+		if ok, err := pub.Verify(payload, signature); err != nil || !ok {
+			return fmt.Errorf("%w: signature of %s", domain.ErrInvalidRotation, k.id)
+		}
+	}
+	return nil
+}
+
+// identityOf returns the node ID and the hex encoded public key of the private key
+func identityOf(key crypto.PrivKey) (string, string, error) {
+	id, err := peer.IDFromPrivateKey(key)
+	if err != nil {
+		return "", "", err
+	}
+	publicKey, err := EncodePublicKey(key.GetPublic())
+	if err != nil {
+		return "", "", err
+	}
+	return id.String(), publicKey, nil
+}
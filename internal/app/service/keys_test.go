@@ -0,0 +1,149 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"chainlink-lite/internal/app/domain"
+
+	"github.com/libp2p/go-libp2p/core/crypto"
+)
+
+func TestAddRotationEffectiveAt(t *testing.T) {
+	tests := []struct {
+		name        string
+		effectiveAt time.Duration
+		valid       bool
+	}{
+		{name: "now", effectiveAt: 0, valid: true},
+		{name: "past", effectiveAt: -time.Minute, valid: true},
+		{name: "within the clock skew", effectiveAt: 30 * time.Second, valid: true},
+		{name: "beyond the clock skew", effectiveAt: 2 * time.Minute},
+		{name: "far future", effectiveAt: 100 * 365 * 24 * time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldKey, _, err := crypto.GenerateEd25519Key(nil)
+			if err != nil {
+				t.Fatalf("generate key: %v", err)
+			}
+			newKey, _, err := crypto.GenerateEd25519Key(nil)
+			if err != nil {
+				t.Fatalf("generate key: %v", err)
+			}
+			rotation, err := NewKeyRotation(oldKey, newKey, time.Now().Add(tt.effectiveAt))
+			if err != nil {
+				t.Fatalf("NewKeyRotation: %v", err)
+			}
+
+			keys := NewKeyRegistry(nil, domain.RevocationList{}, time.Hour, time.Minute)
+			added, err := keys.AddRotation(rotation)
+			if tt.valid && (!added || err != nil) {
+				t.Errorf("AddRotation = %t, %v, want added", added, err)
+			}
+			if !tt.valid && !errors.Is(err, domain.ErrInvalidRotation) {
+				t.Errorf("AddRotation = %t, %v, want %v", added, err, domain.ErrInvalidRotation)
+			}
+			if got := keys.Owner(rotation.NewPeerID) == rotation.OldPeerID; got != tt.valid {
+				t.Errorf("new key succeeds the old key = %t, want %t", got, tt.valid)
+			}
+		})
+	}
+}
+
+// testMember returns a committee member with a new key, and its key
+func testMember(t *testing.T, name string) (domain.Member, crypto.PrivKey) {
+	t.Helper()
+	key, _, err := crypto.GenerateEd25519Key(nil)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	signer, err := NewSignerService(key, domain.SchemeEd25519)
+	if err != nil {
+		t.Fatalf("NewSignerService: %v", err)
+	}
+	return domain.Member{Name: name, PeerID: signer.ID(), PublicKey: signer.PublicKey()}, key
+}
+
+func TestIsKnownHost(t *testing.T) {
+	local, _ := testMember(t, "local")
+	remote, _ := testMember(t, "remote")
+	host, _ := testMember(t, "host of remote")
+	remote.HostPeerID = host.PeerID
+	outsider, _ := testMember(t, "outsider")
+	committee := &domain.Committee{Version: 1, Members: []domain.Member{local, remote}}
+
+	keys := NewKeyRegistry(committee, domain.RevocationList{}, time.Hour, time.Minute)
+	tests := []struct {
+		name   string
+		hostID string
+		known  bool
+	}{
+		{name: "member signing with its host key", hostID: local.PeerID, known: true},
+		{name: "host of a member signing remotely", hostID: host.PeerID, known: true},
+		{name: "outsider", hostID: outsider.PeerID},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := keys.IsKnownHost(tt.hostID); got != tt.known {
+				t.Errorf("IsKnownHost = %t, want %t", got, tt.known)
+			}
+		})
+	}
+
+	// The host of a revoked member is not asked to sign either
+	keys = NewKeyRegistry(committee, domain.RevocationList{Revoked: []domain.RevokedKey{{PeerID: remote.PeerID}}}, time.Hour, time.Minute)
+	if keys.IsKnownHost(host.PeerID) {
+		t.Errorf("IsKnownHost of the host of a revoked member = true")
+	}
+}
+
+func TestCommitteeSize(t *testing.T) {
+	first, firstKey := testMember(t, "first")
+	second, _ := testMember(t, "second")
+	third, _ := testMember(t, "third")
+	committee := &domain.Committee{Version: 1, Members: []domain.Member{first, second, third}}
+	successor, successorKey := testMember(t, "successor of first")
+
+	tests := []struct {
+		name      string
+		revoked   []string
+		rotatedAt time.Duration // Age of the rotation of the first member, 0 when it was not rotated
+		size      int
+	}{
+		{name: "all members", size: 3},
+		{name: "revoked member", revoked: []string{second.PeerID}, size: 2},
+		{name: "rotated within the overlap", rotatedAt: time.Minute, size: 3},
+		{name: "rotated beyond the overlap", rotatedAt: 2 * time.Hour, size: 3},
+		{name: "revoked old key", rotatedAt: time.Minute, revoked: []string{first.PeerID}, size: 3},
+		{name: "revoked successor beyond the overlap", rotatedAt: 2 * time.Hour, revoked: []string{successor.PeerID}, size: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var revocations domain.RevocationList
+			for _, peerID := range tt.revoked {
+				revocations.Revoked = append(revocations.Revoked, domain.RevokedKey{PeerID: peerID})
+			}
+			keys := NewKeyRegistry(committee, revocations, time.Hour, time.Minute)
+			if tt.rotatedAt != 0 {
+				rotation, err := NewKeyRotation(firstKey, successorKey, time.Now().Add(-tt.rotatedAt))
+				if err != nil {
+					t.Fatalf("NewKeyRotation: %v", err)
+				}
+				// A rotation of a revoked key is refused, record it as if the key was revoked after the rotation
+				if _, err := keys.AddRotation(rotation); err != nil {
+					keys.rotations[rotation.OldPeerID] = rotation
+					keys.successors[rotation.NewPeerID] = rotation
+				}
+			}
+			if got := keys.CommitteeSize(); got != tt.size {
+				t.Errorf("CommitteeSize = %d, want %d", got, tt.size)
+			}
+		})
+	}
+
+	if got := NewKeyRegistry(nil, domain.RevocationList{}, time.Hour, time.Minute).CommitteeSize(); got != 0 {
+		t.Errorf("CommitteeSize without a committee = %d, want 0", got)
+	}
+}
@@ -15,13 +15,14 @@ import (
 	"github.com/libp2p/go-libp2p/core/peer"
 )
 
+// Node is the libp2p host of the node, discovering peers on the topics of the feeds it serves
 type Node struct {
-	Host  host.Host
-	DHT   *dht.IpfsDHT
-	Topic string
+	Host   host.Host
+	DHT    *dht.IpfsDHT
+	Topics []string
 }
 
-func NewNode(ctx context.Context, topic string, priv crypto.PrivKey, port int) (*Node, error) {
+func NewNode(ctx context.Context, topics []string, priv crypto.PrivKey, port int) (*Node, error) {
 	host, err := newHost(priv, port)
 	if err != nil {
 		return nil, err
@@ -38,9 +39,9 @@ func NewNode(ctx context.Context, topic string, priv crypto.PrivKey, port int) (
 	}
 
 	return &Node{
-		Host:  host,
-		DHT:   dht,
-		Topic: topic,
+		Host:   host,
+		DHT:    dht,
+		Topics: topics,
 	}, nil
 }
 
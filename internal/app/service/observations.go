@@ -0,0 +1,253 @@
+package service
+
+import (
+	"chainlink-lite/internal/app/domain"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ObservationPool collects the observations of every round, one per node, as they are accepted by the topic validator,
+// the commitments to the observations, and the rounds a report was announced for
+// Nodes that commit to an observation and never reveal it are counted when the round is pruned
+// Rounds are pruned by the publisher of their feed, and once they are older than the retention whatever their feed,
+// so the rounds of a feed whose publisher stopped do not accumulate
+type ObservationPool struct {
+	mu            sync.Mutex
+	rounds        map[roundKey]map[string]domain.Observation // by owner of the observer key
+	commitments   map[roundKey]map[string]domain.Commitment  // by owner of the observer key
+	reported      map[roundKey]struct{}
+	added         map[roundKey]time.Time // Time the first message of the round was pooled
+	missedReveals map[string]uint64      // by owner of the observer key
+	retention     time.Duration
+}
+
+type roundKey struct {
+	feed  string
+	round int64
+}
+
+// NewObservationPool creates the pool, pruning the rounds of every feed once they were pooled for longer than retention
+func NewObservationPool(retention time.Duration) *ObservationPool {
+	return &ObservationPool{
+		rounds:        make(map[roundKey]map[string]domain.Observation),
+		commitments:   make(map[roundKey]map[string]domain.Commitment),
+		reported:      make(map[roundKey]struct{}),
+		added:         make(map[roundKey]time.Time),
+		missedReveals: make(map[string]uint64),
+		retention:     retention,
+	}
+}
+
+// track records the time the first message of the round was pooled
+func (p *ObservationPool) track(key roundKey) {
+	if _, ok := p.added[key]; !ok {
+		p.added[key] = time.Now()
+	}
+}
+
+// Add adds the observation of the node identified by owner
+// Only the first observation of a node for a round is kept, so a node can not swap its observation later
+// Returns false if the node already observed the round
+func (p *ObservationPool) Add(owner string, observation domain.Observation) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key := roundKey{feed: observation.Feed, round: observation.Round}
+	observations, ok := p.rounds[key]
+	if !ok {
+		observations = make(map[string]domain.Observation)
+		p.rounds[key] = observations
+		p.track(key)
+	}
+	if _, ok := observations[owner]; ok {
+		return false
+	}
+	observations[owner] = observation
+	return true
+}
+
+// AddCommitment adds the commitment of the node identified by owner
+// Only the first commitment of a node for a round is kept
+// Returns false if the node already committed to an observation of the round
+func (p *ObservationPool) AddCommitment(owner string, commitment domain.Commitment) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key := roundKey{feed: commitment.Feed, round: commitment.Round}
+	commitments, ok := p.commitments[key]
+	if !ok {
+		commitments = make(map[string]domain.Commitment)
+		p.commitments[key] = commitments
+		p.track(key)
+	}
+	if _, ok := commitments[owner]; ok {
+		return false
+	}
+	commitments[owner] = commitment
+	return true
+}
+
+// CommitmentOf returns the commitment of the node identified by owner for the round
+func (p *ObservationPool) CommitmentOf(feed string, round int64, owner string) (domain.Commitment, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	commitment, ok := p.commitments[roundKey{feed: feed, round: round}][owner]
+	return commitment, ok
+}
+
+// MissedReveals returns the number of pruned rounds every node committed to and did not reveal, by owner
+func (p *ObservationPool) MissedReveals() map[string]uint64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	missed := make(map[string]uint64, len(p.missedReveals))
+	for owner, count := range p.missedReveals {
+		missed[owner] = count
+	}
+	return missed
+}
+
+// Observations returns the observations of the round, sorted by observer
+func (p *ObservationPool) Observations(feed string, round int64) []domain.Observation {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	byOwner := p.rounds[roundKey{feed: feed, round: round}]
+	observations := make([]domain.Observation, 0, len(byOwner))
+	for _, o := range byOwner {
+		observations = append(observations, o)
+	}
+	domain.SortObservations(observations)
+	return observations
+}
+
+// ObservationOf returns the observation of the node identified by owner for the round
+func (p *ObservationPool) ObservationOf(feed string, round int64, owner string) (domain.Observation, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	observation, ok := p.rounds[roundKey{feed: feed, round: round}][owner]
+	return observation, ok
+}
+
+// MarkReported records that a report of the round was announced with the signatures of the write quorum
+func (p *ObservationPool) MarkReported(feed string, round int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	key := roundKey{feed: feed, round: round}
+	p.reported[key] = struct{}{}
+	p.track(key)
+}
+
+// Reported returns true if a report of the round was announced with the signatures of the write quorum
+func (p *ObservationPool) Reported(feed string, round int64) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, ok := p.reported[roundKey{feed: feed, round: round}]
+	return ok
+}
+
+// Prune drops the observations, commitments and reports of the rounds of the feed before round,
+// and of the rounds of any feed pooled for longer than the retention
+// Nodes that committed to one of these rounds without revealing their observation are counted
+func (p *ObservationPool) Prune(feed string, round int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	expired := time.Now().Add(-p.retention)
+	for key, added := range p.added {
+		if (key.feed == feed && key.round < round) || added.Before(expired) {
+			p.drop(key)
+		}
+	}
+}
+
+// drop drops the round, counting the nodes that committed to it without revealing their observation
+func (p *ObservationPool) drop(key roundKey) {
+	for owner := range p.commitments[key] {
+		if _, ok := p.rounds[key][owner]; !ok {
+			p.missedReveals[owner]++
+			log.Warnf("%s committed to round %d of %s without revealing its observation (%d total)", owner, key.round, key.feed, p.missedReveals[owner])
+		}
+	}
+	delete(p.commitments, key)
+	delete(p.rounds, key)
+	delete(p.reported, key)
+	delete(p.added, key)
+}
+
+// VerifyObservation verifies the signature of the observation, and that the public key derives the observer's node ID
+func VerifyObservation(observation *domain.Observation) error {
+	return verifyObserverSignature(observation.Observer, observation.PublicKey, observation.Signature, observation.SigningPayload())
+}
+
+// VerifyCommitment verifies the signature of the commitment, and that the public key derives the observer's node ID
+func VerifyCommitment(commitment *domain.Commitment) error {
+	return verifyObserverSignature(commitment.Observer, commitment.PublicKey, commitment.Signature, commitment.SigningPayload())
+}
+
+// verifyObserverSignature verifies the signature of the observer over the payload
+func verifyObserverSignature(observer string, publicKey string, taggedSignature string, payload []byte) error {
+	pub, err := VerifyIdentity(observer, publicKey)
+	if err != nil {
+		return err
+	}
+
+	scheme, hexSignature, err := domain.ParseSignature(taggedSignature)
+	if err != nil {
+		return fmt.Errorf("%w: observer %s: %v", domain.ErrInvalidSignature, observer, err)
+	}
+	signature, err := hex.DecodeString(hexSignature)
+	if err != nil {
+		return fmt.Errorf("%w: observer %s: %v", domain.ErrInvalidSignature, observer, err)
+	}
+	valid, err := verifyPayload(pub, scheme, payload, signature)
+	if err != nil || !valid {
+		return fmt.Errorf("%w: observer %s", domain.ErrInvalidSignature, observer)
+	}
+	return nil
+}
+
+// VerifyReportObservations checks that the answer of the report is the median of at least minObservations
+// valid observations of its round, from distinct nodes whose keys are active
+func VerifyReportObservations(report *domain.PriceMessage, minObservations int, keys *KeyRegistry) error {
+	if len(report.Observations) < minObservations {
+		return fmt.Errorf("%w: %d observations, %d required", domain.ErrInsufficientObservations, len(report.Observations), minObservations)
+	}
+
+	owners := make(map[string]struct{}, len(report.Observations))
+	for i := range report.Observations {
+		o := &report.Observations[i]
+		if o.Round != report.Round || o.Feed != report.Feed {
+			return fmt.Errorf("%w: observation of %s is for round %d of %s", domain.ErrInvalidObservation, o.Observer, o.Round, o.Feed)
+		}
+		// Sorted observers can not repeat, which keeps the encoding of the observations canonical
+		if i > 0 && o.Observer <= report.Observations[i-1].Observer {
+			return fmt.Errorf("%w: observations are not sorted by observer", domain.ErrInvalidObservation)
+		}
+		if !keys.IsActive(o.Observer, o.PublicKey) {
+			return fmt.Errorf("%w: observer %s", domain.ErrNotCommitteeMember, o.Observer)
+		}
+		owner := keys.Owner(o.Observer)
+		if _, ok := owners[owner]; ok {
+			return fmt.Errorf("%w: %s observed twice", domain.ErrInvalidObservation, owner)
+		}
+		owners[owner] = struct{}{}
+
+		if err := VerifyObservation(o); err != nil {
+			return err
+		}
+	}
+
+	median, err := domain.Median(report.Observations)
+	if err != nil {
+		return err
+	}
+	if !report.Price.Equal(median) {
+		return fmt.Errorf("%w: price %s is not the median %s of the observations", domain.ErrInvalidObservation, report.Price, median)
+	}
+	return nil
+}
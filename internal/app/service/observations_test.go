@@ -0,0 +1,36 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"chainlink-lite/internal/app/domain"
+)
+
+func TestObservationPoolPrune(t *testing.T) {
+	pool := NewObservationPool(time.Hour)
+	for _, key := range []roundKey{{feed: "ETH/USD", round: 1}, {feed: "ETH/USD", round: 2}, {feed: "BTC/USD", round: 1}} {
+		pool.Add("node-a", domain.Observation{Feed: key.feed, Round: key.round})
+	}
+	pool.AddCommitment("node-b", domain.Commitment{Feed: "ETH/USD", Round: 1})
+	pool.MarkReported("BTC/USD", 1)
+
+	// The publisher of a feed prunes its past rounds only
+	pool.Prune("ETH/USD", 2)
+	if len(pool.Observations("ETH/USD", 1)) != 0 || len(pool.Observations("ETH/USD", 2)) != 1 || len(pool.Observations("BTC/USD", 1)) != 1 {
+		t.Errorf("Prune of ETH/USD kept the rounds %v", pool.added)
+	}
+	if got := pool.MissedReveals()["node-b"]; got != 1 {
+		t.Errorf("missed reveals of node-b = %d, want 1", got)
+	}
+
+	// Rounds pooled for longer than the retention are pruned whatever their feed
+	pool.added[roundKey{feed: "BTC/USD", round: 1}] = time.Now().Add(-2 * time.Hour)
+	pool.Prune("ETH/USD", 2)
+	if len(pool.Observations("BTC/USD", 1)) != 0 || pool.Reported("BTC/USD", 1) {
+		t.Errorf("Prune kept the expired round of BTC/USD")
+	}
+	if len(pool.Observations("ETH/USD", 2)) != 1 || len(pool.added) != 1 {
+		t.Errorf("Prune dropped the current round of ETH/USD, rounds %v", pool.added)
+	}
+}
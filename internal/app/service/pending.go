@@ -0,0 +1,207 @@
+package service
+
+import (
+	"bytes"
+	"chainlink-lite/internal/app/domain"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+	log "github.com/sirupsen/logrus"
+)
+
+// PendingReports merges the signatures of every copy of a report, by message ID
+// The leader merges the share every co-signer returns over the signing protocol, and the subscribers merge the announced
+// report, so copies carry different sets of signers. A report may reach the quorum with the signers of all its copies
+// even if no single copy does.
+// Copies are expected to be verified by the topic validator before they are merged.
+// Reports that do not reach the quorum within the TTL after their creation are dropped. The message IDs of finalized
+// and dropped reports are kept in a bounded cache, so their later copies are not processed again.
+type PendingReports struct {
+	keys      *KeyRegistry
+	committee *domain.Committee
+	ttl       time.Duration
+	done      *lru.Cache[string, error] // ErrReportFinalized or ErrReportExpired, by message ID
+
+	mu        sync.Mutex
+	reports   map[string]*domain.PriceMessage // by message ID
+	finalized uint64
+	expired   uint64
+}
+
+// NewPendingReports creates an empty pending report table, counting the keys of a rotated node once
+// Pending reports expire ttl after their creation, and the last cacheSize finalized or expired message IDs are remembered
+func NewPendingReports(keys *KeyRegistry, ttl time.Duration, cacheSize int) (*PendingReports, error) {
+	if ttl <= 0 {
+		return nil, fmt.Errorf("pending report TTL must be positive")
+	}
+	done, err := lru.New[string, error](cacheSize)
+	if err != nil {
+		return nil, err
+	}
+	return &PendingReports{
+		keys:      keys,
+		committee: keys.Committee(),
+		ttl:       ttl,
+		done:      done,
+		reports:   make(map[string]*domain.PriceMessage),
+	}, nil
+}
+
+// Start drops the expired pending reports until the context is done
+func (p *PendingReports) Start(ctx context.Context) {
+	ticker := time.NewTicker(p.ttl / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if expired := p.Expire(now); expired > 0 {
+				log.Debugf("Dropped %d pending reports that did not reach the quorum", expired)
+			}
+		}
+	}
+}
+
+// Merge adds the signatures of the copy to the pending report with the same message ID
+// Returns a copy of the merged report, and true if the copy added signers to it
+// Copies of finalized or expired reports are refused with ErrReportFinalized or ErrReportExpired
+func (p *PendingReports) Merge(report *domain.PriceMessage) (*domain.PriceMessage, bool, error) {
+	// Check under the lock, so a copy merged while the report is finalized is not added back to the table
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err, ok := p.done.Get(report.MessageID); ok {
+		return nil, false, fmt.Errorf("%w: message %s", err, report.MessageID)
+	}
+	if p.isExpired(report, time.Now()) {
+		return nil, false, fmt.Errorf("%w: message %s", domain.ErrReportExpired, report.MessageID)
+	}
+
+	pending, ok := p.reports[report.MessageID]
+	if !ok {
+		pending = cloneReport(report)
+		p.reports[report.MessageID] = pending
+		return cloneReport(pending), true, nil
+	}
+	// Signatures cover the signing payload, they can only be merged if both copies sign the same report
+	if !bytes.Equal(pending.SigningPayload(), report.SigningPayload()) {
+		return nil, false, fmt.Errorf("%w: message %s", domain.ErrConflictingReport, report.MessageID)
+	}
+
+	before := pending.SignatureCount()
+	if pending.Aggregate != nil || report.Aggregate != nil {
+		if err := p.mergeAggregate(pending, report); err != nil {
+			return nil, false, err
+		}
+	} else {
+		p.mergeSignatures(pending, report)
+	}
+	return cloneReport(pending), pending.SignatureCount() > before, nil
+}
+
+// mergeSignatures appends the signatures of the signers of the copy that are not listed yet
+func (p *PendingReports) mergeSignatures(pending *domain.PriceMessage, report *domain.PriceMessage) {
+	owners := make(map[string]struct{}, len(pending.Signers))
+	for _, signer := range pending.Signers {
+		owners[p.keys.Owner(signer)] = struct{}{}
+	}
+	for i, signer := range report.Signers {
+		owner := p.keys.Owner(signer)
+		if _, ok := owners[owner]; ok {
+			continue
+		}
+		owners[owner] = struct{}{}
+		pending.Signers = append(pending.Signers, signer)
+		pending.PublicKeys = append(pending.PublicKeys, report.PublicKeys[i])
+		pending.Signatures = append(pending.Signatures, report.Signatures[i])
+	}
+}
+
+// mergeAggregate combines the aggregate signatures of both copies
+func (p *PendingReports) mergeAggregate(pending *domain.PriceMessage, report *domain.PriceMessage) error {
+	if pending.Aggregate == nil || report.Aggregate == nil || p.committee == nil {
+		return fmt.Errorf("%w: message %s mixes individual and aggregate signatures", domain.ErrConflictingReport, report.MessageID)
+	}
+	aggregate, err := MergeAggregateSignatures(pending.Aggregate, report.Aggregate, p.committee)
+	if err != nil {
+		return err
+	}
+	pending.Aggregate = aggregate
+	return nil
+}
+
+// Finalize drops the pending report once it reached the quorum, and refuses its later copies
+func (p *PendingReports) Finalize(messageID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.done.Get(messageID); ok {
+		return
+	}
+	delete(p.reports, messageID)
+	p.done.Add(messageID, domain.ErrReportFinalized)
+	p.finalized++
+}
+
+// Prune drops the pending reports of the rounds of the feed before round, they can no longer be written
+func (p *PendingReports) Prune(feed string, round int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for id, report := range p.reports {
+		if report.Feed == feed && report.Round < round {
+			p.drop(id)
+		}
+	}
+}
+
+// Expire drops the pending reports created longer than the TTL before now
+// Returns the number of dropped reports
+func (p *PendingReports) Expire(now time.Time) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	expired := 0
+	for id, report := range p.reports {
+		if p.isExpired(report, now) {
+			p.drop(id)
+			expired++
+		}
+	}
+	return expired
+}
+
+// Counts returns the number of reports that reached the quorum, and the number of reports dropped before they did
+func (p *PendingReports) Counts() (finalized uint64, expired uint64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.finalized, p.expired
+}
+
+// drop removes the pending report that did not reach the quorum, and refuses its later copies
+func (p *PendingReports) drop(messageID string) {
+	delete(p.reports, messageID)
+	p.done.Add(messageID, domain.ErrReportExpired)
+	p.expired++
+}
+
+// isExpired returns true if the report was created longer than the TTL before now
+func (p *PendingReports) isExpired(report *domain.PriceMessage, now time.Time) bool {
+	return now.Sub(time.Unix(report.CreatedAt, 0)) > p.ttl
+}
+
+// cloneReport returns a copy of the report that shares no slice with it
+func cloneReport(report *domain.PriceMessage) *domain.PriceMessage {
+	clone := *report
+	clone.Signers = append([]string(nil), report.Signers...)
+	clone.PublicKeys = append([]string(nil), report.PublicKeys...)
+	clone.Signatures = append([]string(nil), report.Signatures...)
+	clone.Observations = append([]domain.Observation(nil), report.Observations...)
+	if report.Aggregate != nil {
+		aggregate := *report.Aggregate
+		clone.Aggregate = &aggregate
+	}
+	return &clone
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"chainlink-lite/internal/app/domain"
+)
+
+// newTestPendingReports returns an empty pending report table without a committee
+func newTestPendingReports(t *testing.T, ttl time.Duration) *PendingReports {
+	t.Helper()
+	keys := NewKeyRegistry(nil, domain.RevocationList{}, time.Hour, time.Minute)
+	pending, err := NewPendingReports(keys, ttl, 16)
+	if err != nil {
+		t.Fatalf("NewPendingReports: %v", err)
+	}
+	return pending
+}
+
+// pendingCopy returns a copy of the report signed by the signers, created at createdAt
+func pendingCopy(t *testing.T, messageID string, price string, createdAt time.Time, signers ...string) *domain.PriceMessage {
+	t.Helper()
+	p, err := domain.ParsePrice(price)
+	if err != nil {
+		t.Fatalf("ParsePrice: %v", err)
+	}
+	report := &domain.PriceMessage{
+		MessageID: messageID,
+		Feed:      "ETH/USD",
+		Round:     1,
+		Price:     p,
+		Publisher: "publisher",
+		CreatedAt: createdAt.Unix(),
+	}
+	for _, signer := range signers {
+		report.Signers = append(report.Signers, signer)
+		report.PublicKeys = append(report.PublicKeys, "key-"+signer)
+		report.Signatures = append(report.Signatures, "signature-"+signer)
+	}
+	return report
+}
+
+func TestPendingReportsMerge(t *testing.T) {
+	pending := newTestPendingReports(t, time.Minute)
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		report  *domain.PriceMessage
+		signers []string
+		added   bool
+		err     error
+	}{
+		{name: "first copy", report: pendingCopy(t, "report", "3456.78", now, "a"), signers: []string{"a"}, added: true},
+		{name: "copy with a new signer", report: pendingCopy(t, "report", "3456.78", now, "a", "b"), signers: []string{"a", "b"}, added: true},
+		{name: "copy without a new signer", report: pendingCopy(t, "report", "3456.78", now, "b"), signers: []string{"a", "b"}},
+		{name: "conflicting copy", report: pendingCopy(t, "report", "3500", now, "c"), err: domain.ErrConflictingReport},
+		{name: "expired copy", report: pendingCopy(t, "old", "3456.78", now.Add(-2*time.Minute), "a"), err: domain.ErrReportExpired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, added, err := pending.Merge(tt.report)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Merge = %v, want %v", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			if added != tt.added {
+				t.Errorf("Merge added signers = %t, want %t", added, tt.added)
+			}
+			if len(merged.Signers) != len(tt.signers) {
+				t.Fatalf("merged signers = %v, want %v", merged.Signers, tt.signers)
+			}
+			for i, signer := range tt.signers {
+				if merged.Signers[i] != signer || merged.Signatures[i] != "signature-"+signer {
+					t.Errorf("merged signers = %v, want %v", merged.Signers, tt.signers)
+				}
+			}
+		})
+	}
+
+	// The merged report is a copy, changing it leaves the pending report as it is
+	merged, _, err := pending.Merge(pendingCopy(t, "report", "3456.78", now))
+	if err != nil {
+		t.Fatalf("Merge: %v", err)
+	}
+	merged.Signers[0] = "changed"
+	if merged, _, _ = pending.Merge(pendingCopy(t, "report", "3456.78", now)); merged.Signers[0] != "a" {
+		t.Errorf("pending report shares its signers with the merged copy")
+	}
+}
+
+func TestPendingReportsFinalize(t *testing.T) {
+	pending := newTestPendingReports(t, time.Minute)
+	now := time.Now()
+
+	if _, _, err := pending.Merge(pendingCopy(t, "report", "3456.78", now, "a")); err != nil {
+		t.Fatalf("Merge: %v", err)
+	}
+	pending.Finalize("report")
+	pending.Finalize("report")
+	if finalized, expired := pending.Counts(); finalized != 1 || expired != 0 {
+		t.Errorf("Counts = %d, %d, want 1, 0", finalized, expired)
+	}
+	if _, _, err := pending.Merge(pendingCopy(t, "report", "3456.78", now, "b")); !errors.Is(err, domain.ErrReportFinalized) {
+		t.Errorf("Merge of a finalized report = %v, want %v", err, domain.ErrReportFinalized)
+	}
+	// A finalized report is not expired later
+	if expired := pending.Expire(now.Add(2 * time.Minute)); expired != 0 {
+		t.Errorf("Expire = %d after the report was finalized, want 0", expired)
+	}
+}
+
+// Copies merged while the report is finalized are never added back to the pending reports
+func TestPendingReportsMergeDuringFinalize(t *testing.T) {
+	pending := newTestPendingReports(t, time.Minute)
+	now := time.Now()
+
+	report := pendingCopy(t, "report", "3456.78", now, "a")
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				pending.Merge(report)
+			}
+		}()
+	}
+	pending.Finalize("report")
+	wg.Wait()
+
+	pending.mu.Lock()
+	_, ok := pending.reports["report"]
+	pending.mu.Unlock()
+	if ok {
+		t.Errorf("finalized report is pending again")
+	}
+}
+
+func TestPendingReportsExpire(t *testing.T) {
+	pending := newTestPendingReports(t, time.Minute)
+	now := time.Now()
+
+	for _, report := range []*domain.PriceMessage{
+		pendingCopy(t, "old", "3456.78", now.Add(-50*time.Second), "a"),
+		pendingCopy(t, "recent", "3456.78", now, "a"),
+	} {
+		if _, _, err := pending.Merge(report); err != nil {
+			t.Fatalf("Merge: %v", err)
+		}
+	}
+
+	if expired := pending.Expire(now.Add(30 * time.Second)); expired != 1 {
+		t.Errorf("Expire = %d, want 1", expired)
+	}
+	if finalized, expired := pending.Counts(); finalized != 0 || expired != 1 {
+		t.Errorf("Counts = %d, %d, want 0, 1", finalized, expired)
+	}
+	if _, _, err := pending.Merge(pendingCopy(t, "old", "3456.78", now.Add(-50*time.Second), "b")); !errors.Is(err, domain.ErrReportExpired) {
+		t.Errorf("Merge of an expired report = %v, want %v", err, domain.ErrReportExpired)
+	}
+	if _, _, err := pending.Merge(pendingCopy(t, "recent", "3456.78", now, "b")); err != nil {
+		t.Errorf("Merge of a pending report: %v", err)
+	}
+
+	if _, err := NewPendingReports(NewKeyRegistry(nil, domain.RevocationList{}, time.Hour, time.Minute), 0, 16); err == nil {
+		t.Errorf("NewPendingReports without a TTL succeeded")
+	}
+}
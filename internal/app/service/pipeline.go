@@ -0,0 +1,230 @@
+package service
+
+import (
+	"chainlink-lite/internal/app/domain"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+// Task is a task of a job pipeline
+type Task interface {
+	// Run returns the output of the task, given the results of its inputs in the order of its spec
+	// Failed inputs are passed with their error, so a task may tolerate some of them
+	Run(ctx context.Context, vars TaskVars, inputs []TaskResult) (interface{}, error)
+}
+
+// TaskVars are the variables of a run of a pipeline
+type TaskVars struct {
+	Job   string
+	Feed  string
+	Round int64
+}
+
+// TaskResult is the output of a task, or the error it failed with
+type TaskResult struct {
+	Value interface{}
+	Err   error
+}
+
+// TaskFactory creates the task of a spec, and checks its parameters
+type TaskFactory func(spec domain.TaskSpec) (Task, error)
+
+// Pipeline runs the DAG of tasks of a job
+// Tasks run as soon as all their inputs are available, so independent tasks, e.g. HTTP requests, run concurrently
+type Pipeline struct {
+	job   string
+	feed  string
+	tasks []pipelineTask // Sorted so every task comes after its inputs
+	index map[string]int // Position of the tasks, by ID
+}
+
+// pipelineTask is a task of a pipeline, with the positions of its inputs
+type pipelineTask struct {
+	spec   domain.TaskSpec
+	task   Task
+	inputs []int
+}
+
+// NewPipeline creates the pipeline of the job, with the task factories by task type
+func NewPipeline(job domain.JobSpec, factories map[string]TaskFactory) (*Pipeline, error) {
+	if err := job.Validate(); err != nil {
+		return nil, err
+	}
+	specs, err := domain.SortTasks(job.Tasks)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Pipeline{job: job.Name, feed: job.Feed, index: make(map[string]int, len(specs))}
+	for i, spec := range specs {
+		factory, ok := factories[spec.Type]
+		if !ok {
+			return nil, fmt.Errorf("%w: job %s: no %s task", domain.ErrInvalidJob, job.Name, spec.Type)
+		}
+		task, err := factory(spec)
+		if err != nil {
+			return nil, fmt.Errorf("%w: job %s: task %s: %v", domain.ErrInvalidJob, job.Name, spec.ID, err)
+		}
+		inputs := make([]int, len(spec.Inputs))
+		for j, input := range spec.Inputs {
+			inputs[j] = p.index[input]
+		}
+		p.tasks = append(p.tasks, pipelineTask{spec: spec, task: task, inputs: inputs})
+		p.index[spec.ID] = i
+	}
+	return p, nil
+}
+
+// Output returns the ID of the task whose output is the output of the pipeline
+func (p *Pipeline) Output() string {
+	return p.tasks[len(p.tasks)-1].spec.ID
+}
+
+// Task returns the spec of the task with the ID
+func (p *Pipeline) Task(id string) (domain.TaskSpec, bool) {
+	i, ok := p.index[id]
+	if !ok {
+		return domain.TaskSpec{}, false
+	}
+	return p.tasks[i].spec, true
+}
+
+// Run runs the tasks the task with the ID depends on for the round, and returns its result and the runs of every task
+func (p *Pipeline) Run(ctx context.Context, round int64, until string) (TaskResult, []domain.TaskRun) {
+	last, ok := p.index[until]
+	if !ok {
+		return TaskResult{Err: fmt.Errorf("%w: no task %s", domain.ErrInvalidJob, until)}, nil
+	}
+
+	// Only run the tasks the output depends on
+	needed := make([]bool, last+1)
+	needed[last] = true
+	for i := last; i >= 0; i-- {
+		if needed[i] {
+			for _, input := range p.tasks[i].inputs {
+				needed[input] = true
+			}
+		}
+	}
+
+	vars := TaskVars{Job: p.job, Feed: p.feed, Round: round}
+	results := make([]TaskResult, last+1)
+	done := make([]chan struct{}, last+1)
+	runs := make([]*domain.TaskRun, last+1)
+	var wg sync.WaitGroup
+	for i := 0; i <= last; i++ {
+		done[i] = make(chan struct{})
+		if !needed[i] {
+			continue
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer close(done[i])
+
+			t := p.tasks[i]
+			inputs := make([]TaskResult, len(t.inputs))
+			for j, input := range t.inputs {
+				<-done[input]
+				inputs[j] = results[input]
+			}
+			startedAt := time.Now()
+			value, err := t.task.Run(ctx, vars, inputs)
+			results[i] = TaskResult{Value: value, Err: err}
+			runs[i] = newTaskRun(vars, t.spec, inputs, results[i], startedAt)
+		}(i)
+	}
+	wg.Wait()
+
+	recorded := make([]domain.TaskRun, 0, len(runs))
+	for _, run := range runs {
+		if run != nil {
+			recorded = append(recorded, *run)
+		}
+	}
+	return results[last], recorded
+}
+
+// newTaskRun records the run of the task with its inputs and result
+func newTaskRun(vars TaskVars, spec domain.TaskSpec, inputs []TaskResult, result TaskResult, startedAt time.Time) *domain.TaskRun {
+	values := make([]interface{}, len(inputs))
+	for i, input := range inputs {
+		values[i] = recordedValue(input)
+	}
+	run := &domain.TaskRun{
+		Job:        vars.Job,
+		Feed:       vars.Feed,
+		Round:      vars.Round,
+		TaskID:     spec.ID,
+		TaskType:   spec.Type,
+		Inputs:     encodeRecord(values),
+		Output:     encodeRecord(recordedValue(TaskResult{Value: result.Value})),
+		StartedAt:  startedAt,
+		FinishedAt: time.Now(),
+	}
+	if result.Err != nil {
+		run.Error = result.Err.Error()
+	}
+	return run
+}
+
+// recordedValue returns the value of the result, or its error
+func recordedValue(result TaskResult) interface{} {
+	if result.Err != nil {
+		return map[string]string{"error": result.Err.Error()}
+	}
+	return result.Value
+}
+
+// encodeRecord encodes a recorded value as JSON, or as the JSON string of its text if it can not be encoded
+func encodeRecord(value interface{}) json.RawMessage {
+	data, err := json.Marshal(value)
+	if err != nil {
+		data, _ = json.Marshal(fmt.Sprint(value))
+	}
+	return data
+}
+
+// DecodeTaskParams decodes the parameters of the task into params, refusing unknown parameters
+// Numbers and strings are converted into each other, so decimals may be written either way
+func DecodeTaskParams(spec domain.TaskSpec, params interface{}) error {
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		ErrorUnused:      true,
+		WeaklyTypedInput: true,
+		TagName:          "yaml",
+		Result:           params,
+	})
+	if err != nil {
+		return err
+	}
+	if err := decoder.Decode(spec.Params); err != nil {
+		var decodeErr *mapstructure.Error
+		if errors.As(err, &decodeErr) {
+			return fmt.Errorf("invalid parameters: %s", strings.Join(decodeErr.Errors, ", "))
+		}
+		return err
+	}
+	return nil
+}
+
+// TaskInputs returns the values of the inputs, or an error if the number of inputs is not n or an input failed
+func TaskInputs(inputs []TaskResult, n int) ([]interface{}, error) {
+	if len(inputs) != n {
+		return nil, fmt.Errorf("%d inputs, expected %d", len(inputs), n)
+	}
+	values := make([]interface{}, len(inputs))
+	for i, input := range inputs {
+		if input.Err != nil {
+			return nil, fmt.Errorf("input %d failed: %w", i, input.Err)
+		}
+		values[i] = input.Value
+	}
+	return values, nil
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"chainlink-lite/internal/app/domain"
+)
+
+// taskFunc is a task running a function
+type taskFunc func(ctx context.Context, vars TaskVars, inputs []TaskResult) (interface{}, error)
+
+func (f taskFunc) Run(ctx context.Context, vars TaskVars, inputs []TaskResult) (interface{}, error) {
+	return f(ctx, vars, inputs)
+}
+
+// testPipeline returns the pipeline of a job with two http tasks, a median, a sign and a gossip task,
+// the tasks of each type running the function of the type
+func testPipeline(t *testing.T, funcs map[string]taskFunc) *Pipeline {
+	t.Helper()
+	decimals := uint8(8)
+	job := domain.JobSpec{
+		Name:        "link-usd",
+		Feed:        "LINK/USD",
+		Topic:       "oracle/link-usd",
+		Schedule:    30 * time.Second,
+		WriteQuorum: "3",
+		Decimals:    &decimals,
+		Tasks: []domain.TaskSpec{
+			{ID: "a", Type: domain.TaskHTTP},
+			{ID: "b", Type: domain.TaskHTTP},
+			{ID: "median", Type: domain.TaskMedian, Inputs: []string{"a", "b"}},
+			{ID: "sign", Type: domain.TaskSign, Inputs: []string{"median"}},
+			{ID: "gossip", Type: domain.TaskGossip, Inputs: []string{"sign"}},
+		},
+	}
+	factories := make(map[string]TaskFactory, len(funcs))
+	for taskType, f := range funcs {
+		f := f
+		factories[taskType] = func(domain.TaskSpec) (Task, error) { return f, nil }
+	}
+	pipeline, err := NewPipeline(job, factories)
+	if err != nil {
+		t.Fatalf("NewPipeline: %v", err)
+	}
+	return pipeline
+}
+
+// passFirst returns the result of the first input
+func passFirst(_ context.Context, _ TaskVars, inputs []TaskResult) (interface{}, error) {
+	return inputs[0].Value, inputs[0].Err
+}
+
+func TestPipelineRunsIndependentTasksConcurrently(t *testing.T) {
+	// Every http task waits for the other one to start, which deadlocks unless they run concurrently
+	var started sync.WaitGroup
+	started.Add(2)
+	pipeline := testPipeline(t, map[string]taskFunc{
+		domain.TaskHTTP: func(ctx context.Context, _ TaskVars, _ []TaskResult) (interface{}, error) {
+			started.Done()
+			waited := make(chan struct{})
+			go func() {
+				started.Wait()
+				close(waited)
+			}()
+			select {
+			case <-waited:
+				return "1", nil
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		},
+		domain.TaskMedian: passFirst,
+		domain.TaskSign:   passFirst,
+		domain.TaskGossip: passFirst,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	result, runs := pipeline.Run(ctx, 7, pipeline.Output())
+	if result.Err != nil || result.Value != "1" {
+		t.Fatalf("Run = %v, %v, want 1", result.Value, result.Err)
+	}
+	if len(runs) != 5 {
+		t.Errorf("%d task runs, want 5", len(runs))
+	}
+	for _, run := range runs {
+		if run.Job != "link-usd" || run.Feed != "LINK/USD" || run.Round != 7 {
+			t.Errorf("run of %s is of job %s, feed %s, round %d", run.TaskID, run.Job, run.Feed, run.Round)
+		}
+	}
+}
+
+func TestPipelineRunsNeededTasksOnly(t *testing.T) {
+	var mu sync.Mutex
+	ran := make(map[string]int)
+	count := func(taskType string) taskFunc {
+		return func(_ context.Context, _ TaskVars, inputs []TaskResult) (interface{}, error) {
+			mu.Lock()
+			ran[taskType]++
+			mu.Unlock()
+			if len(inputs) == 0 {
+				return "1", nil
+			}
+			return inputs[0].Value, nil
+		}
+	}
+	pipeline := testPipeline(t, map[string]taskFunc{
+		domain.TaskHTTP:   count(domain.TaskHTTP),
+		domain.TaskMedian: count(domain.TaskMedian),
+		domain.TaskSign:   count(domain.TaskSign),
+		domain.TaskGossip: count(domain.TaskGossip),
+	})
+
+	// The price is computed without signing nor gossiping it
+	result, runs := pipeline.Run(context.Background(), 0, "median")
+	if result.Err != nil || result.Value != "1" {
+		t.Fatalf("Run = %v, %v, want 1", result.Value, result.Err)
+	}
+	if len(runs) != 3 || ran[domain.TaskSign] != 0 || ran[domain.TaskGossip] != 0 || ran[domain.TaskHTTP] != 2 {
+		t.Errorf("%d task runs, ran %v, want the http and median tasks only", len(runs), ran)
+	}
+
+	result, runs = pipeline.Run(context.Background(), 0, "unknown")
+	if !errors.Is(result.Err, domain.ErrInvalidJob) || len(runs) != 0 {
+		t.Errorf("Run of an unknown task = %v with %d runs, want %v", result.Err, len(runs), domain.ErrInvalidJob)
+	}
+}
+
+func TestPipelinePassesFailedInputs(t *testing.T) {
+	errDown := errors.New("source down")
+	var failed []TaskResult
+	pipeline := testPipeline(t, map[string]taskFunc{
+		domain.TaskHTTP: func(_ context.Context, _ TaskVars, _ []TaskResult) (interface{}, error) {
+			return nil, errDown
+		},
+		domain.TaskMedian: func(_ context.Context, _ TaskVars, inputs []TaskResult) (interface{}, error) {
+			failed = inputs
+			return nil, inputs[0].Err
+		},
+		domain.TaskSign:   passFirst,
+		domain.TaskGossip: passFirst,
+	})
+
+	result, runs := pipeline.Run(context.Background(), 1, pipeline.Output())
+	if len(failed) != 2 || !errors.Is(failed[0].Err, errDown) || !errors.Is(failed[1].Err, errDown) {
+		t.Errorf("median task took %v, want the errors of both inputs", failed)
+	}
+	// A task that does not tolerate failed inputs fails in turn, up to the output
+	if !errors.Is(result.Err, errDown) {
+		t.Errorf("Run = %v, want %v", result.Err, errDown)
+	}
+	for _, run := range runs {
+		if run.Error == "" {
+			t.Errorf("run of %s has no error", run.TaskID)
+		}
+	}
+}
@@ -4,30 +4,55 @@ import (
 	"chainlink-lite/internal/app/domain"
 	"context"
 	"encoding/json"
+	"fmt"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	log "github.com/sirupsen/logrus"
 
 	pubsub "github.com/libp2p/go-libp2p-pubsub"
 	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/peer"
 )
 
 type PubSubService struct {
-	gossip    *pubsub.PubSub
-	topic     *pubsub.Topic
-	sub       *pubsub.Subscription
-	ctx       context.Context
-	host      host.Host
-	topicName string
+	gossip       *pubsub.PubSub
+	topic        *pubsub.Topic
+	sub          *pubsub.Subscription
+	rotations    *pubsub.Topic
+	observations *pubsub.Topic
+	commitments  *pubsub.Topic
+	relays       []pubsub.RelayCancelFunc
+	ctx          context.Context
+	host         host.Host
+	topicName    string
+	validator    *MessageValidator
 }
 
-func NewPubSubService(ctx context.Context, topicName string, host host.Host) (*PubSubService, error) {
-	gossip, err := pubsub.NewGossipSub(ctx, host,
+// NewGossipSub creates the GossipSub router of the host, shared by the topics of every feed
+// Peers are scored on the topics of every feed
+func NewGossipSub(ctx context.Context, host host.Host, topicNames []string) (*pubsub.PubSub, error) {
+	scored := make([]string, 0, 4*len(topicNames))
+	for _, topicName := range topicNames {
+		scored = append(scored, topicName, RotationsTopicName(topicName), ObservationsTopicName(topicName), CommitmentsTopicName(topicName))
+	}
+	return pubsub.NewGossipSub(ctx, host,
 		pubsub.WithMessageSignaturePolicy(pubsub.StrictSign),
 		pubsub.WithStrictSignatureVerification(true),
 		pubsub.WithPeerExchange(true),
-		pubsub.WithMessageSigning(true))
-	if err != nil {
+		pubsub.WithMessageSigning(true),
+		pubsub.WithPeerScore(peerScoreParams(scored...)))
+}
+
+// NewPubSubService joins the topic of a feed on the router and subscribes to it,
+// and relays the key rotations, observations and commitments topics of the feed
+// Every message is checked by the validator before it is delivered or relayed
+func NewPubSubService(ctx context.Context, gossip *pubsub.PubSub, topicName string, host host.Host, validator *MessageValidator) (*PubSubService, error) {
+	rotationsTopicName := RotationsTopicName(topicName)
+	observationsTopicName := ObservationsTopicName(topicName)
+	commitmentsTopicName := CommitmentsTopicName(topicName)
+
+	if err := gossip.RegisterTopicValidator(topicName, validator.Validate); err != nil {
 		return nil, err
 	}
 
@@ -41,13 +66,36 @@ func NewPubSubService(ctx context.Context, topicName string, host host.Host) (*P
 		return nil, err
 	}
 
+	// Rotations, observations and commitments are only consumed by the validator,
+	// which accepts the successor keys and adds observations and commitments to the pool
+	rotations, relayRotations, err := joinRelay(gossip, rotationsTopicName, validator.ValidateRotation)
+	if err != nil {
+		return nil, err
+	}
+	observations, relayObservations, err := joinRelay(gossip, observationsTopicName, validator.ValidateObservation)
+	if err != nil {
+		relayRotations()
+		return nil, err
+	}
+	commitments, relayCommitments, err := joinRelay(gossip, commitmentsTopicName, validator.ValidateCommitment)
+	if err != nil {
+		relayRotations()
+		relayObservations()
+		return nil, err
+	}
+
 	return &PubSubService{
-		ctx:       ctx,
-		gossip:    gossip,
-		topic:     topic,
-		sub:       sub,
-		host:      host,
-		topicName: topicName,
+		ctx:          ctx,
+		gossip:       gossip,
+		topic:        topic,
+		sub:          sub,
+		rotations:    rotations,
+		observations: observations,
+		commitments:  commitments,
+		relays:       []pubsub.RelayCancelFunc{relayRotations, relayObservations, relayCommitments},
+		host:         host,
+		topicName:    topicName,
+		validator:    validator,
 	}, nil
 }
 
@@ -62,6 +110,7 @@ func (p *PubSubService) Publish(msg *domain.PriceMessage) error {
 }
 
 // Receive receives a message from the pubsub topic
+// Messages are decoded and checked by the topic validator before they are delivered
 // If skipFromSelf is true, messages from the current node are skipped
 func (p *PubSubService) Receive(skipFromSelf bool) (*domain.PriceMessage, error) {
 	// Receive message from topic
@@ -76,34 +125,127 @@ func (p *PubSubService) Receive(skipFromSelf bool) (*domain.PriceMessage, error)
 		return nil, nil
 	}
 
-	var priceMsg domain.PriceMessage
-	err = json.Unmarshal(msg.Data, &priceMsg)
+	validated, ok := msg.ValidatorData.(*domain.PriceMessage)
+	if !ok {
+		return nil, fmt.Errorf("message %s was not decoded by the topic validator", msg.ID)
+	}
+
+	// The validator data is shared by every subscription, hand out a copy
+	return cloneReport(validated), nil
+}
+
+// PublishRotation publishes a key rotation announcement to the key rotations topic
+func (p *PubSubService) PublishRotation(rotation domain.KeyRotation) error {
+	data, err := json.Marshal(rotation)
 	if err != nil {
-		return nil, err
+		return err
 	}
+	return p.rotations.Publish(p.ctx, data)
+}
 
-	// Validate message
-	err = ValidateMessage(priceMsg)
+// PublishObservation publishes a signed observation to the observations topic
+func (p *PubSubService) PublishObservation(observation domain.Observation) error {
+	data, err := json.Marshal(observation)
 	if err != nil {
-		log.Info("Invalid message received: ", priceMsg, err)
-		return nil, err
+		return err
 	}
+	return p.observations.Publish(p.ctx, data)
+}
 
-	return &priceMsg, nil
+// PublishCommitment publishes a signed commitment to an observation to the commitments topic
+func (p *PubSubService) PublishCommitment(commitment domain.Commitment) error {
+	data, err := json.Marshal(commitment)
+	if err != nil {
+		return err
+	}
+	return p.commitments.Publish(p.ctx, data)
 }
 
 func (p *PubSubService) Close() {
 	p.sub.Cancel()
+	for _, cancel := range p.relays {
+		cancel()
+	}
 }
 
 func (p *PubSubService) GetTopicName() string {
 	return p.topicName
 }
 
+// Peers returns the peers subscribed to the topic of the feed
+func (p *PubSubService) Peers() []peer.ID {
+	return p.topic.ListPeers()
+}
+
 func (p *PubSubService) GetNodeID() string {
 	return p.host.ID().String()
 }
 
+// RejectionCounts returns the number of messages rejected or ignored by the topic validator, per reason
+func (p *PubSubService) RejectionCounts() map[string]uint64 {
+	return p.validator.Counts()
+}
+
+// RotationsTopicName returns the name of the topic key rotations are announced on
+func RotationsTopicName(topicName string) string {
+	return topicName + "/key-rotations"
+}
+
+// ObservationsTopicName returns the name of the topic observations are published on
+func ObservationsTopicName(topicName string) string {
+	return topicName + "/observations"
+}
+
+// CommitmentsTopicName returns the name of the topic commitments to observations are published on
+func CommitmentsTopicName(topicName string) string {
+	return topicName + "/commitments"
+}
+
+// joinRelay joins the topic with its validator and relays its messages without subscribing to it
+func joinRelay(gossip *pubsub.PubSub, topicName string, validate pubsub.ValidatorEx) (*pubsub.Topic, pubsub.RelayCancelFunc, error) {
+	if err := gossip.RegisterTopicValidator(topicName, validate); err != nil {
+		return nil, nil, err
+	}
+	topic, err := gossip.Join(topicName)
+	if err != nil {
+		return nil, nil, err
+	}
+	cancel, err := topic.Relay()
+	if err != nil {
+		return nil, nil, err
+	}
+	return topic, cancel, nil
+}
+
+// peerScoreParams penalizes peers that forward messages rejected by the topic validators,
+// until they are graylisted and their messages are dropped
+func peerScoreParams(topicNames ...string) (*pubsub.PeerScoreParams, *pubsub.PeerScoreThresholds) {
+	topics := make(map[string]*pubsub.TopicScoreParams, len(topicNames))
+	for _, topicName := range topicNames {
+		topics[topicName] = &pubsub.TopicScoreParams{
+			SkipAtomicValidation:           true,
+			TopicWeight:                    1,
+			TimeInMeshQuantum:              time.Second,
+			InvalidMessageDeliveriesWeight: -100,
+			InvalidMessageDeliveriesDecay:  pubsub.ScoreParameterDecay(time.Hour),
+		}
+	}
+	params := &pubsub.PeerScoreParams{
+		SkipAtomicValidation: true,
+		Topics:               topics,
+		DecayInterval:        time.Second,
+		DecayToZero:          0.01,
+		RetainScore:          time.Hour,
+	}
+	thresholds := &pubsub.PeerScoreThresholds{
+		SkipAtomicValidation: true,
+		GossipThreshold:      -100,
+		PublishThreshold:     -500,
+		GraylistThreshold:    -1000,
+	}
+	return params, thresholds
+}
+
 func ValidateMessage(priceMsg domain.PriceMessage) error {
 	validate := validator.New()
 	return validate.Struct(priceMsg)
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"chainlink-lite/internal/app/domain"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// QuorumTracker computes the number of signatures that finalize a report of a feed from the current membership of the committee
+type QuorumTracker struct {
+	feed   string
+	keys   *KeyRegistry
+	quorum domain.Quorum
+
+	mu        sync.Mutex
+	threshold int
+}
+
+// NewQuorumTracker creates the tracker of the quorum of the feed over the committee of the key registry
+func NewQuorumTracker(feed string, keys *KeyRegistry, quorum domain.Quorum) *QuorumTracker {
+	return &QuorumTracker{feed: feed, keys: keys, quorum: quorum}
+}
+
+// Threshold returns the number of signatures required to finalize a report
+// It is recomputed from the current membership of the committee, and logged whenever it changes
+func (q *QuorumTracker) Threshold() int {
+	size := q.keys.CommitteeSize()
+	threshold := q.quorum.Threshold(size)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if threshold != q.threshold {
+		if q.quorum.Relative() {
+			log.Infof("Write quorum %s of %s is %d signatures with %d committee members", q.quorum, q.feed, threshold, size)
+		} else {
+			log.Infof("Write quorum of %s is %d signatures", q.feed, threshold)
+		}
+		q.threshold = threshold
+	}
+	return threshold
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"chainlink-lite/internal/app/domain"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ReferencePrices returns the price the node itself observed, to check the reports it is asked to co-sign
+// The observation the node published for the round is used when there is one. Otherwise the price is fetched
+// from the price source of the node, and reused for maxAge so a burst of reports does not hit the price api.
+type ReferencePrices struct {
+	source       domain.PriceSource
+	feed         string
+	nodeID       string
+	keys         *KeyRegistry
+	observations *ObservationPool
+	maxAge       time.Duration
+
+	mu        sync.Mutex
+	price     domain.Price
+	fetchedAt time.Time
+}
+
+// NewReferencePrices creates the reference prices of the node for the feed it observes, fetched from the price source
+func NewReferencePrices(source domain.PriceSource, feed string, nodeID string, keys *KeyRegistry,
+	observations *ObservationPool, maxAge time.Duration) *ReferencePrices {
+	return &ReferencePrices{
+		source:       source,
+		feed:         feed,
+		nodeID:       nodeID,
+		keys:         keys,
+		observations: observations,
+		maxAge:       maxAge,
+	}
+}
+
+// Price returns the reference price of the round of the feed
+func (r *ReferencePrices) Price(ctx context.Context, feed string, round int64) (domain.Price, error) {
+	if observation, ok := r.observations.ObservationOf(feed, round, r.keys.Owner(r.nodeID)); ok {
+		return observation.Price, nil
+	}
+	if feed != r.feed {
+		return domain.Price{}, fmt.Errorf("%w: no observation of %s", domain.ErrFailedToFetchPrice, feed)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.price.IsZero() && time.Since(r.fetchedAt) < r.maxAge {
+		return r.price, nil
+	}
+	price, err := r.source.FetchPrice(ctx, feed)
+	if err != nil {
+		return domain.Price{}, err
+	}
+	r.price, r.fetchedAt = price, time.Now()
+	return price, nil
+}
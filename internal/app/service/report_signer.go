@@ -0,0 +1,120 @@
+package service
+
+import (
+	"chainlink-lite/internal/app/domain"
+	"context"
+	"fmt"
+)
+
+// ReportSigner adds the node's signature to reports, either as an individual signature of the domain.Signer,
+// or aggregated into the BLS signature of the committee
+type ReportSigner struct {
+	signer    domain.Signer
+	bls       *BLSSigner
+	committee *domain.Committee
+}
+
+// NewReportSigner creates a report signer
+// If blsSigner is not nil, signatures are aggregated with BLS over the committee, which is then required
+func NewReportSigner(signer domain.Signer, blsSigner *BLSSigner, committee *domain.Committee) (*ReportSigner, error) {
+	if blsSigner != nil {
+		if committee == nil {
+			return nil, fmt.Errorf("BLS aggregation requires a committee")
+		}
+		m, ok := committee.Member(signer.ID())
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", domain.ErrNotCommitteeMember, signer.ID())
+		}
+		if m.BLSPublicKey != blsSigner.PublicKey() {
+			return nil, fmt.Errorf("BLS public key of %s in the committee does not match the node key", signer.ID())
+		}
+	}
+	return &ReportSigner{signer: signer, bls: blsSigner, committee: committee}, nil
+}
+
+// ID returns the node ID the signatures are attributed to
+func (r *ReportSigner) ID() string {
+	return r.signer.ID()
+}
+
+// AddSignature signs the report and adds the signature to it
+func (r *ReportSigner) AddSignature(ctx context.Context, report *domain.PriceMessage) error {
+	if r.bls != nil {
+		return r.bls.AddSignature(report, r.committee, r.signer.ID())
+	}
+
+	signature, err := r.signer.SignReport(ctx, report)
+	if err != nil {
+		return err
+	}
+	report.Signers = append(report.Signers, r.signer.ID())
+	report.PublicKeys = append(report.PublicKeys, r.signer.PublicKey())
+	report.Signatures = append(report.Signatures, signature)
+	return nil
+}
+
+// Share signs the report and returns the signature of the node only, for the proposer of the report to merge
+func (r *ReportSigner) Share(ctx context.Context, report *domain.PriceMessage) (domain.SignatureShare, error) {
+	if r.bls != nil {
+		// Aggregate into an empty aggregate, so the share only flags this node
+		own := *report
+		own.Aggregate = nil
+		if err := r.bls.AddSignature(&own, r.committee, r.signer.ID()); err != nil {
+			return domain.SignatureShare{}, err
+		}
+		return domain.SignatureShare{Aggregate: own.Aggregate}, nil
+	}
+
+	signature, err := r.signer.SignReport(ctx, report)
+	if err != nil {
+		return domain.SignatureShare{}, err
+	}
+	return domain.SignatureShare{Signer: r.signer.ID(), PublicKey: r.signer.PublicKey(), Signature: signature}, nil
+}
+
+// SignObservation signs the observation as the observer
+func (r *ReportSigner) SignObservation(ctx context.Context, observation *domain.Observation) error {
+	observation.Observer = r.signer.ID()
+	observation.PublicKey = r.signer.PublicKey()
+	signature, err := r.signer.SignObservation(ctx, observation)
+	if err != nil {
+		return err
+	}
+	observation.Signature = signature
+	return nil
+}
+
+// SignCommitment signs the commitment to an observation as the observer
+func (r *ReportSigner) SignCommitment(ctx context.Context, commitment *domain.Commitment) error {
+	commitment.Observer = r.signer.ID()
+	commitment.PublicKey = r.signer.PublicKey()
+	signature, err := r.signer.SignCommitment(ctx, commitment)
+	if err != nil {
+		return err
+	}
+	commitment.Signature = signature
+	return nil
+}
+
+// ResolveSigners lists the signers of an aggregate signature and their BLS public keys in Signers and PublicKeys,
+// so the report can be stored and re-verified
+func (r *ReportSigner) ResolveSigners(report *domain.PriceMessage) error {
+	if report.Aggregate == nil {
+		return nil
+	}
+	if r.committee == nil {
+		return fmt.Errorf("resolving aggregate signers requires a committee")
+	}
+
+	signers, err := VerifyAggregateSignature(report, r.committee)
+	if err != nil {
+		return err
+	}
+	report.Signers = make([]string, 0, len(signers))
+	report.PublicKeys = make([]string, 0, len(signers))
+	for _, m := range signers {
+		report.Signers = append(report.Signers, m.PeerID)
+		report.PublicKeys = append(report.PublicKeys, m.BLSPublicKey)
+	}
+	return nil
+}
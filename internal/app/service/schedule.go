@@ -0,0 +1,126 @@
+package service
+
+import (
+	"chainlink-lite/internal/app/domain"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// Schedule is the timing and the leader rotation of the aggregation rounds
+// A round starts every interval and is identified by the number of intervals since the unix epoch.
+// Nodes observe at the start of the round, and the leader of epoch 0 proposes the report after the observation window.
+// If no report was seen after the leader timeout, the leader of the next epoch proposes, and so on.
+// With a commit window, nodes first publish a commitment to their observation, and reveal it once the commit window closed.
+type Schedule struct {
+	keys              *KeyRegistry
+	committee         *domain.Committee
+	interval          time.Duration
+	commitWindow      time.Duration
+	observationWindow time.Duration
+	leaderTimeout     time.Duration
+}
+
+// NewSchedule creates the schedule of the rounds, with leaders taken from the committee of the key registry
+// A committee is required, as the nodes must agree on the leaders or every node would propose its own report
+// A zero commit window disables commitments, observations are then published at the start of the round
+func NewSchedule(keys *KeyRegistry, interval time.Duration, commitWindow time.Duration, observationWindow time.Duration,
+	leaderTimeout time.Duration) (*Schedule, error) {
+	if keys.Committee() == nil || keys.Committee().Size() == 0 {
+		return nil, fmt.Errorf("rounds need a committee to agree on their leaders")
+	}
+	if interval <= 0 || observationWindow < 0 || leaderTimeout <= 0 {
+		return nil, fmt.Errorf("interval and leader timeout must be positive")
+	}
+	if observationWindow+leaderTimeout > interval {
+		return nil, fmt.Errorf("observation window %s and leader timeout %s do not fit in the interval %s", observationWindow, leaderTimeout, interval)
+	}
+	if commitWindow < 0 || (commitWindow > 0 && commitWindow >= observationWindow) {
+		return nil, fmt.Errorf("commit window %s must end before the observation window %s", commitWindow, observationWindow)
+	}
+	return &Schedule{
+		keys:              keys,
+		committee:         keys.Committee(),
+		interval:          interval,
+		commitWindow:      commitWindow,
+		observationWindow: observationWindow,
+		leaderTimeout:     leaderTimeout,
+	}, nil
+}
+
+// Round returns the round in progress at t
+func (s *Schedule) Round(t time.Time) int64 {
+	return t.UnixNano() / int64(s.interval)
+}
+
+// RoundStart returns the time the round starts
+func (s *Schedule) RoundStart(round int64) time.Time {
+	return time.Unix(0, round*int64(s.interval))
+}
+
+// CommitReveal returns true if nodes commit to their observations before revealing them
+func (s *Schedule) CommitReveal() bool {
+	return s.commitWindow > 0
+}
+
+// RevealStart returns the time the commit window of the round closes, and the observations are revealed
+func (s *Schedule) RevealStart(round int64) time.Time {
+	return s.RoundStart(round).Add(s.commitWindow)
+}
+
+// EpochStart returns the time the leader of the epoch may propose the report of the round
+func (s *Schedule) EpochStart(round int64, epoch int) time.Time {
+	return s.RoundStart(round).Add(s.observationWindow + time.Duration(epoch)*s.leaderTimeout)
+}
+
+// CollectionDeadline returns the time the leader of the epoch stops collecting signatures,
+// so its report is announced before the leader of the next epoch takes over
+func (s *Schedule) CollectionDeadline(round int64, epoch int) time.Time {
+	return s.EpochStart(round, epoch+1).Add(-s.leaderTimeout / 4)
+}
+
+// Epochs returns the number of epochs of a round
+// Every member leads at most one epoch, and every epoch starts before the next round
+func (s *Schedule) Epochs() int {
+	epochs := int((s.interval - s.observationWindow - 1) / s.leaderTimeout)
+	if epochs < 1 {
+		epochs = 1
+	}
+	if epochs > s.committee.Size() {
+		epochs = s.committee.Size()
+	}
+	return epochs
+}
+
+// Leader returns the node ID of the committee member that leads the epoch of the round of the feed
+// The leader of epoch 0 is chosen by hashing the feed and the round over the committee,
+// and the following epochs are led by the next members, so a failover never picks the same leader twice
+func (s *Schedule) Leader(feed string, round int64, epoch int) string {
+	h := sha256.New()
+	h.Write([]byte(feed))
+	_ = binary.Write(h, binary.BigEndian, round)
+	start := binary.BigEndian.Uint64(h.Sum(nil)[:8]) % uint64(s.committee.Size())
+	return s.committee.Members[(start+uint64(epoch))%uint64(s.committee.Size())].PeerID
+}
+
+// Leads returns true if the node leads the epoch of the round of the feed
+// The owner of the node key is compared, so a leader keeps leading after rotating its key
+func (s *Schedule) Leads(feed string, round int64, epoch int, peerID string) bool {
+	return s.keys.Owner(peerID) == s.Leader(feed, round, epoch)
+}
+
+// CheckProposal checks that the report was proposed by the leader of its epoch, once the epoch started
+func (s *Schedule) CheckProposal(report *domain.PriceMessage, now time.Time) error {
+	if report.Epoch < 0 || report.Epoch >= s.Epochs() {
+		return fmt.Errorf("%w: epoch %d out of range", domain.ErrNotLeader, report.Epoch)
+	}
+	if !s.Leads(report.Feed, report.Round, report.Epoch, report.Publisher) {
+		return fmt.Errorf("%w: %s does not lead epoch %d of round %d", domain.ErrNotLeader, report.Publisher, report.Epoch, report.Round)
+	}
+	// Allow for some clock skew between the leader and this node
+	if now.Add(s.leaderTimeout / 2).Before(s.EpochStart(report.Round, report.Epoch)) {
+		return fmt.Errorf("%w: epoch %d of round %d has not started", domain.ErrEarlyProposal, report.Epoch, report.Round)
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"chainlink-lite/internal/app/domain"
+
+	"github.com/libp2p/go-libp2p/core/crypto"
+)
+
+// testCommittee returns a committee of n members, and the keys of the members
+func testCommittee(t *testing.T, n int) (*domain.Committee, []crypto.PrivKey) {
+	t.Helper()
+	committee := &domain.Committee{Version: 1}
+	keys := make([]crypto.PrivKey, n)
+	for i := range keys {
+		var member domain.Member
+		member, keys[i] = testMember(t, "member")
+		committee.Members = append(committee.Members, member)
+	}
+	return committee, keys
+}
+
+func TestNewScheduleRequiresCommittee(t *testing.T) {
+	keys := NewKeyRegistry(nil, domain.RevocationList{}, time.Hour, time.Minute)
+	if _, err := NewSchedule(keys, time.Minute, 0, 10*time.Second, 10*time.Second); err == nil {
+		t.Errorf("NewSchedule without a committee succeeded")
+	}
+	keys = NewKeyRegistry(&domain.Committee{Version: 1}, domain.RevocationList{}, time.Hour, time.Minute)
+	if _, err := NewSchedule(keys, time.Minute, 0, 10*time.Second, 10*time.Second); err == nil {
+		t.Errorf("NewSchedule with an empty committee succeeded")
+	}
+}
+
+func TestScheduleEpochs(t *testing.T) {
+	tests := []struct {
+		name    string
+		members int
+		want    int
+	}{
+		// (60s - 10s) / 10s epochs start before the next round
+		{name: "bound by the interval", members: 10, want: 4},
+		{name: "bound by the committee", members: 3, want: 3},
+		{name: "single member", members: 1, want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			committee, _ := testCommittee(t, tt.members)
+			keys := NewKeyRegistry(committee, domain.RevocationList{}, time.Hour, time.Minute)
+			schedule, err := NewSchedule(keys, time.Minute, 0, 10*time.Second, 10*time.Second)
+			if err != nil {
+				t.Fatalf("NewSchedule: %v", err)
+			}
+			if got := schedule.Epochs(); got != tt.want {
+				t.Errorf("Epochs = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduleLeaderRotation(t *testing.T) {
+	committee, _ := testCommittee(t, 4)
+	keys := NewKeyRegistry(committee, domain.RevocationList{}, time.Hour, time.Minute)
+	schedule, err := NewSchedule(keys, time.Minute, 0, 10*time.Second, 10*time.Second)
+	if err != nil {
+		t.Fatalf("NewSchedule: %v", err)
+	}
+
+	leaders := make(map[string]int)
+	for round := int64(1); round <= 100; round++ {
+		// Every epoch of a round has a single leader, and a failover never picks the same leader twice
+		seen := make(map[string]struct{})
+		for epoch := 0; epoch < schedule.Epochs(); epoch++ {
+			leader := schedule.Leader("ETH/USD", round, epoch)
+			if _, ok := seen[leader]; ok {
+				t.Fatalf("%s leads two epochs of round %d", leader, round)
+			}
+			seen[leader] = struct{}{}
+			for _, m := range committee.Members {
+				if got := schedule.Leads("ETH/USD", round, epoch, m.PeerID); got != (m.PeerID == leader) {
+					t.Errorf("Leads of %s in epoch %d of round %d = %t, leader is %s", m.PeerID, epoch, round, got, leader)
+				}
+			}
+		}
+		leaders[schedule.Leader("ETH/USD", round, 0)]++
+	}
+	// The first epoch is led by every member in turn
+	if len(leaders) != committee.Size() {
+		t.Errorf("%d members led the first epoch of 100 rounds, want %d", len(leaders), committee.Size())
+	}
+}
+
+func TestScheduleLeaderAfterRotation(t *testing.T) {
+	committee, memberKeys := testCommittee(t, 1)
+	keys := NewKeyRegistry(committee, domain.RevocationList{}, time.Hour, time.Minute)
+	schedule, err := NewSchedule(keys, time.Minute, 0, 10*time.Second, 10*time.Second)
+	if err != nil {
+		t.Fatalf("NewSchedule: %v", err)
+	}
+	successor, _ := testMember(t, "successor")
+	if schedule.Leads("ETH/USD", 1, 0, successor.PeerID) {
+		t.Fatalf("Leads of an unknown key = true")
+	}
+
+	newKey, _, err := crypto.GenerateEd25519Key(nil)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	rotation, err := NewKeyRotation(memberKeys[0], newKey, time.Now())
+	if err != nil {
+		t.Fatalf("NewKeyRotation: %v", err)
+	}
+	if _, err := keys.AddRotation(rotation); err != nil {
+		t.Fatalf("AddRotation: %v", err)
+	}
+	// A leader keeps leading after rotating its key
+	if !schedule.Leads("ETH/USD", 1, 0, rotation.NewPeerID) {
+		t.Errorf("Leads of the successor key = false")
+	}
+}
+
+func TestScheduleCheckProposal(t *testing.T) {
+	committee, _ := testCommittee(t, 3)
+	keys := NewKeyRegistry(committee, domain.RevocationList{}, time.Hour, time.Minute)
+	schedule, err := NewSchedule(keys, time.Minute, 0, 10*time.Second, 10*time.Second)
+	if err != nil {
+		t.Fatalf("NewSchedule: %v", err)
+	}
+	round := int64(1000)
+	leader := schedule.Leader("ETH/USD", round, 1)
+	var other string
+	for _, m := range committee.Members {
+		if m.PeerID != leader {
+			other = m.PeerID
+		}
+	}
+
+	tests := []struct {
+		name      string
+		publisher string
+		epoch     int
+		now       time.Time
+		want      error
+	}{
+		{name: "leader", publisher: leader, epoch: 1, now: schedule.EpochStart(round, 1)},
+		{name: "other member", publisher: other, epoch: 1, now: schedule.EpochStart(round, 1), want: domain.ErrNotLeader},
+		{name: "epoch out of range", publisher: leader, epoch: schedule.Epochs(), now: schedule.EpochStart(round, 1), want: domain.ErrNotLeader},
+		{name: "epoch not started", publisher: leader, epoch: 1, now: schedule.EpochStart(round, 0), want: domain.ErrEarlyProposal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := &domain.PriceMessage{Feed: "ETH/USD", Round: round, Epoch: tt.epoch, Publisher: tt.publisher}
+			if err := schedule.CheckProposal(report, tt.now); !errors.Is(err, tt.want) {
+				t.Errorf("CheckProposal = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
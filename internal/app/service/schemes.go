@@ -0,0 +1,256 @@
+package service
+
+import (
+	"chainlink-lite/internal/app/domain"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/crypto/pb"
+	"golang.org/x/crypto/sha3"
+)
+
+// EIP-712 domain and type of the price report, so EVM contracts can verify stored reports with ecrecover
+var (
+	eip712DomainTypeHash = keccak256([]byte("EIP712Domain(string name,string version,uint256 chainId,address verifyingContract)"))
+	eip712ReportTypeHash = keccak256([]byte("PriceReport(string messageId,string feed,int256 answer,uint8 decimals,string publisher,uint64 createdAt,uint64 round,uint32 epoch,bytes32 observationsHash)"))
+	eip712DomainName     = "chainlink-lite"
+	eip712DomainVersion  = strconv.Itoa(int(domain.SigningPayloadVersion))
+
+	eip712DomainMu   sync.RWMutex
+	eip712DomainHash = eip712DomainSeparator(eip712DomainName, eip712DomainVersion, 0, [20]byte{})
+)
+
+// EIP712Domain is the domain the reports of the secp256k1-eip712 scheme are signed in, so a signature is only valid
+// on the chain and for the contract that verifies the reports
+// ChainID is the EIP-155 chain ID, and VerifyingContract the hex address of the contract
+type EIP712Domain struct {
+	ChainID           uint64
+	VerifyingContract string
+}
+
+// SetEIP712Domain sets the domain the reports of the secp256k1-eip712 scheme are signed and verified in
+// Every node of the network must use the same domain
+func SetEIP712Domain(d EIP712Domain) error {
+	contract, err := parseAddress(d.VerifyingContract)
+	if err != nil {
+		return fmt.Errorf("invalid verifying contract: %v", err)
+	}
+	if d.ChainID == 0 {
+		return fmt.Errorf("no chain ID")
+	}
+	eip712DomainMu.Lock()
+	defer eip712DomainMu.Unlock()
+	eip712DomainHash = eip712DomainSeparator(eip712DomainName, eip712DomainVersion, d.ChainID, contract)
+	return nil
+}
+
+// ConfigureEIP712Domain sets the domain when one is configured, the secp256k1-eip712 scheme requires it
+func ConfigureEIP712Domain(scheme domain.SignatureScheme, d EIP712Domain) error {
+	if d == (EIP712Domain{}) {
+		if scheme == domain.SchemeSecp256k1EIP712 {
+			return fmt.Errorf("the %s scheme requires the chain ID and verifying contract of its domain", scheme)
+		}
+		return nil
+	}
+	return SetEIP712Domain(d)
+}
+
+// eip712DomainSeparator returns the hash of the EIP-712 domain
+func eip712DomainSeparator(name string, version string, chainID uint64, contract [20]byte) []byte {
+	encodedChainID := make([]byte, 32)
+	binary.BigEndian.PutUint64(encodedChainID[24:], chainID)
+	encodedContract := make([]byte, 32)
+	copy(encodedContract[12:], contract[:])
+	return keccak256(eip712DomainTypeHash, keccak256([]byte(name)), keccak256([]byte(version)), encodedChainID, encodedContract)
+}
+
+// parseAddress decodes a 0x prefixed hex address
+func parseAddress(s string) ([20]byte, error) {
+	var address [20]byte
+	raw, ok := strings.CutPrefix(s, "0x")
+	if !ok || len(raw) != 2*len(address) {
+		return address, fmt.Errorf("%q is not a 0x prefixed 20 byte hex address", s)
+	}
+	if _, err := hex.Decode(address[:], []byte(raw)); err != nil {
+		return address, fmt.Errorf("%q is not a 0x prefixed 20 byte hex address", s)
+	}
+	return address, nil
+}
+
+// KeyTypeForScheme returns the libp2p key type that signs with the scheme
+func KeyTypeForScheme(scheme domain.SignatureScheme) (int, error) {
+	switch scheme {
+	case domain.SchemeECDSA:
+		return crypto.ECDSA, nil
+	case domain.SchemeEd25519:
+		return crypto.Ed25519, nil
+	case domain.SchemeSecp256k1EIP191, domain.SchemeSecp256k1EIP712:
+		return crypto.Secp256k1, nil
+	default:
+		return 0, fmt.Errorf("unknown signature scheme %q", scheme)
+	}
+}
+
+// signReport signs the report with the key under the scheme
+// Returns the raw signature bytes
+func signReport(key crypto.PrivKey, scheme domain.SignatureScheme, report *domain.PriceMessage) ([]byte, error) {
+	if err := checkKeyType(key.Type(), scheme); err != nil {
+		return nil, err
+	}
+
+	switch scheme {
+	case domain.SchemeECDSA, domain.SchemeEd25519:
+		return key.Sign(report.SigningPayload())
+	case domain.SchemeSecp256k1EIP191, domain.SchemeSecp256k1EIP712:
+		return signRecoverable(key, ethereumDigest(scheme, report))
+	default:
+		return nil, fmt.Errorf("unknown signature scheme %q", scheme)
+	}
+}
+
+// signPayload signs the canonical payload of an observation or a commitment with the key under the scheme
+// They are not meant to be verified on chain, so both secp256k1 schemes sign their EIP-191 digest
+func signPayload(key crypto.PrivKey, scheme domain.SignatureScheme, payload []byte) ([]byte, error) {
+	if err := checkKeyType(key.Type(), scheme); err != nil {
+		return nil, err
+	}
+
+	switch scheme {
+	case domain.SchemeECDSA, domain.SchemeEd25519:
+		return key.Sign(payload)
+	case domain.SchemeSecp256k1EIP191, domain.SchemeSecp256k1EIP712:
+		return signRecoverable(key, eip191Digest(payload))
+	default:
+		return nil, fmt.Errorf("unknown signature scheme %q", scheme)
+	}
+}
+
+// verifyPayload verifies a raw signature over the payload of an observation or a commitment
+// against the public key under the scheme
+func verifyPayload(pub crypto.PubKey, scheme domain.SignatureScheme, payload []byte, signature []byte) (bool, error) {
+	if err := checkKeyType(pub.Type(), scheme); err != nil {
+		return false, err
+	}
+
+	switch scheme {
+	case domain.SchemeECDSA, domain.SchemeEd25519:
+		return pub.Verify(payload, signature)
+	case domain.SchemeSecp256k1EIP191, domain.SchemeSecp256k1EIP712:
+		return verifyRecoverable(pub, eip191Digest(payload), signature)
+	default:
+		return false, fmt.Errorf("unknown signature scheme %q", scheme)
+	}
+}
+
+// verifyReport verifies a raw signature over the report against the public key under the scheme
+func verifyReport(pub crypto.PubKey, scheme domain.SignatureScheme, report *domain.PriceMessage, signature []byte) (bool, error) {
+	if err := checkKeyType(pub.Type(), scheme); err != nil {
+		return false, err
+	}
+
+	switch scheme {
+	case domain.SchemeECDSA, domain.SchemeEd25519:
+		return pub.Verify(report.SigningPayload(), signature)
+	case domain.SchemeSecp256k1EIP191, domain.SchemeSecp256k1EIP712:
+		return verifyRecoverable(pub, ethereumDigest(scheme, report), signature)
+	default:
+		return false, fmt.Errorf("unknown signature scheme %q", scheme)
+	}
+}
+
+// signRecoverable signs the digest with the secp256k1 key, in the [r || s || v] layout expected by ecrecover
+func signRecoverable(key crypto.PrivKey, digest []byte) ([]byte, error) {
+	priv := (*secp256k1.PrivateKey)(key.(*crypto.Secp256k1PrivateKey))
+	compact := ecdsa.SignCompact(priv, digest, false)
+	// Reorder [v || r || s] into the [r || s || v] layout expected by ecrecover
+	return append(compact[1:], compact[0]), nil
+}
+
+// verifyRecoverable recovers the signer of the digest and compares it to the public key
+func verifyRecoverable(pub crypto.PubKey, digest []byte, signature []byte) (bool, error) {
+	if len(signature) != 65 {
+		return false, fmt.Errorf("recoverable signature must be 65 bytes, got %d", len(signature))
+	}
+	// Reorder [r || s || v] back into the [v || r || s] layout of compact signatures
+	compact := append([]byte{signature[64]}, signature[:64]...)
+	recovered, _, err := ecdsa.RecoverCompact(compact, digest)
+	if err != nil {
+		return false, err
+	}
+	return pub.Equals((*crypto.Secp256k1PublicKey)(recovered)), nil
+}
+
+// EthereumAddress returns the checksum-free hex address of a secp256k1 public key, as returned by ecrecover
+func EthereumAddress(pub crypto.PubKey) (string, error) {
+	key, ok := pub.(*crypto.Secp256k1PublicKey)
+	if !ok {
+		return "", fmt.Errorf("key type %s has no ethereum address", pub.Type())
+	}
+	uncompressed := (*secp256k1.PublicKey)(key).SerializeUncompressed()
+	return "0x" + hex.EncodeToString(keccak256(uncompressed[1:])[12:]), nil
+}
+
+// ethereumDigest returns the 32 byte digest signed by the secp256k1 schemes
+func ethereumDigest(scheme domain.SignatureScheme, report *domain.PriceMessage) []byte {
+	if scheme == domain.SchemeSecp256k1EIP712 {
+		createdAt := make([]byte, 32)
+		binary.BigEndian.PutUint64(createdAt[24:], uint64(report.CreatedAt))
+		round := make([]byte, 32)
+		binary.BigEndian.PutUint64(round[24:], uint64(report.Round))
+		epoch := make([]byte, 32)
+		binary.BigEndian.PutUint32(epoch[28:], uint32(report.Epoch))
+		// The price is encoded as the int256 answer and the decimals of an EVM aggregator
+		answer := report.Price.Answer().FillBytes(make([]byte, 32))
+		decimals := make([]byte, 32)
+		decimals[31] = report.Price.Decimals()
+		structHash := keccak256(eip712ReportTypeHash,
+			keccak256([]byte(report.MessageID)), keccak256([]byte(report.Feed)), answer, decimals,
+			keccak256([]byte(report.Publisher)), createdAt, round, epoch, keccak256(report.ObservationsPayload()))
+		eip712DomainMu.RLock()
+		defer eip712DomainMu.RUnlock()
+		return eip712Digest(eip712DomainHash, structHash)
+	}
+	return eip191Digest(report.SigningPayload())
+}
+
+// eip712Digest returns the EIP-712 digest of the hash of a struct in the domain
+func eip712Digest(domainSeparator []byte, structHash []byte) []byte {
+	return keccak256([]byte("\x19\x01"), domainSeparator, structHash)
+}
+
+// eip191Digest returns the EIP-191 personal message digest of the keccak256 hash of the payload
+func eip191Digest(payload []byte) []byte {
+	return personalMessageDigest(keccak256(payload))
+}
+
+// personalMessageDigest returns the digest of the message signed by eth_sign, prefixed with its length
+func personalMessageDigest(message []byte) []byte {
+	return keccak256([]byte("\x19Ethereum Signed Message:\n"+strconv.Itoa(len(message))), message)
+}
+
+// checkKeyType checks that the key type can sign with the scheme
+func checkKeyType(keyType pb.KeyType, scheme domain.SignatureScheme) error {
+	expected, err := KeyTypeForScheme(scheme)
+	if err != nil {
+		return err
+	}
+	if int(keyType) != expected {
+		return fmt.Errorf("%s key can not be used with signature scheme %s", keyType, scheme)
+	}
+	return nil
+}
+
+func keccak256(data ...[]byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	for _, d := range data {
+		h.Write(d)
+	}
+	return h.Sum(nil)
+}
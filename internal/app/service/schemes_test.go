@@ -0,0 +1,205 @@
+package service
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	"chainlink-lite/internal/app/domain"
+
+	"github.com/libp2p/go-libp2p/core/crypto"
+)
+
+// testSecp256k1Key returns the secp256k1 key of the hex private key
+func testSecp256k1Key(t *testing.T, privateKey string) crypto.PrivKey {
+	t.Helper()
+	raw, err := hex.DecodeString(privateKey)
+	if err != nil {
+		t.Fatalf("decode private key: %v", err)
+	}
+	key, err := crypto.UnmarshalSecp256k1PrivateKey(raw)
+	if err != nil {
+		t.Fatalf("UnmarshalSecp256k1PrivateKey: %v", err)
+	}
+	return key
+}
+
+// decodeHex decodes a 0x prefixed hex string
+func decodeHex(t *testing.T, s string) []byte {
+	t.Helper()
+	raw, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		t.Fatalf("decode %q: %v", s, err)
+	}
+	return raw
+}
+
+// restoreEIP712Domain restores the domain when the test ends
+func restoreEIP712Domain(t *testing.T) {
+	t.Helper()
+	eip712DomainMu.RLock()
+	hash := eip712DomainHash
+	eip712DomainMu.RUnlock()
+	t.Cleanup(func() {
+		eip712DomainMu.Lock()
+		eip712DomainHash = hash
+		eip712DomainMu.Unlock()
+	})
+}
+
+// The vector of web3.eth.accounts.sign("Some data", key)
+func TestPersonalMessageDigest(t *testing.T) {
+	key := testSecp256k1Key(t, "4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318")
+
+	address, err := EthereumAddress(key.GetPublic())
+	if err != nil {
+		t.Fatalf("EthereumAddress: %v", err)
+	}
+	if want := "0x2c7536e3605d9c16a7a3d7b1898e529396a65c23"; address != want {
+		t.Errorf("EthereumAddress = %s, want %s", address, want)
+	}
+
+	digest := personalMessageDigest([]byte("Some data"))
+	if want := decodeHex(t, "0x1da44b586eb0729ff70a73c326926f6ed5a25f5b056e7f47fbc6e58d86871655"); !bytes.Equal(digest, want) {
+		t.Errorf("personalMessageDigest = %x, want %x", digest, want)
+	}
+
+	signature, err := signRecoverable(key, digest)
+	if err != nil {
+		t.Fatalf("signRecoverable: %v", err)
+	}
+	want := decodeHex(t, "0xb91467e570a6466aa9e9876cbcd013baba02900b8979d43fe208a4a4f339f5fd"+
+		"6007e74cd82e037b800186422fc2da167c747ef045e5d18a5f5d4300f8e1a029"+"1c")
+	if !bytes.Equal(signature, want) {
+		t.Errorf("signRecoverable = %x, want %x", signature, want)
+	}
+}
+
+// The Ether Mail example of the EIP-712 specification
+func TestEIP712Digest(t *testing.T) {
+	key := testSecp256k1Key(t, "c85ef7d79691fe79573b1a7064c19c1a9819ebdbd1faaab1a8ec92344438aaf4")
+
+	contract, err := parseAddress("0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC")
+	if err != nil {
+		t.Fatalf("parseAddress: %v", err)
+	}
+	separator := eip712DomainSeparator("Ether Mail", "1", 1, contract)
+	if want := decodeHex(t, "0xf2cee375fa42b42143804025fc449deafd50cc031ca257e0b194a650a912090f"); !bytes.Equal(separator, want) {
+		t.Errorf("eip712DomainSeparator = %x, want %x", separator, want)
+	}
+
+	structHash := decodeHex(t, "0xc52c0ee5d84264471806290a3f2c4cecfc5490626bf912d01f240d7a274b371e")
+	digest := eip712Digest(separator, structHash)
+	if want := decodeHex(t, "0xbe609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2"); !bytes.Equal(digest, want) {
+		t.Errorf("eip712Digest = %x, want %x", digest, want)
+	}
+
+	signature, err := signRecoverable(key, digest)
+	if err != nil {
+		t.Fatalf("signRecoverable: %v", err)
+	}
+	want := decodeHex(t, "0x4355c47d63924e8a72e509b65029052eb6c299d53a04e167c5775fd466751c9d"+
+		"07299936d304c153f6443dfa05f40ff007d72911b6f72307f996231605b91562"+"1c")
+	if !bytes.Equal(signature, want) {
+		t.Errorf("signRecoverable = %x, want %x", signature, want)
+	}
+}
+
+func TestSetEIP712Domain(t *testing.T) {
+	restoreEIP712Domain(t)
+
+	tests := []struct {
+		name   string
+		domain EIP712Domain
+		valid  bool
+	}{
+		{name: "valid", domain: EIP712Domain{ChainID: 1, VerifyingContract: "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"}, valid: true},
+		{name: "no chain ID", domain: EIP712Domain{VerifyingContract: "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"}},
+		{name: "no contract", domain: EIP712Domain{ChainID: 1}},
+		{name: "no prefix", domain: EIP712Domain{ChainID: 1, VerifyingContract: "CcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"}},
+		{name: "short contract", domain: EIP712Domain{ChainID: 1, VerifyingContract: "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccc"}},
+		{name: "not hex", domain: EIP712Domain{ChainID: 1, VerifyingContract: "0xZcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := SetEIP712Domain(tt.domain)
+			if tt.valid && err != nil {
+				t.Errorf("SetEIP712Domain: %v", err)
+			}
+			if !tt.valid && err == nil {
+				t.Errorf("SetEIP712Domain succeeded")
+			}
+		})
+	}
+
+	if err := ConfigureEIP712Domain(domain.SchemeSecp256k1EIP712, EIP712Domain{}); err == nil {
+		t.Errorf("ConfigureEIP712Domain of the secp256k1-eip712 scheme without a domain succeeded")
+	}
+	if err := ConfigureEIP712Domain(domain.SchemeSecp256k1EIP191, EIP712Domain{}); err != nil {
+		t.Errorf("ConfigureEIP712Domain of the secp256k1-eip191 scheme without a domain: %v", err)
+	}
+}
+
+func TestRecoverableReportSignature(t *testing.T) {
+	restoreEIP712Domain(t)
+	if err := SetEIP712Domain(EIP712Domain{ChainID: 1, VerifyingContract: "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"}); err != nil {
+		t.Fatalf("SetEIP712Domain: %v", err)
+	}
+
+	price, err := domain.ParsePrice("3456.78")
+	if err != nil {
+		t.Fatalf("ParsePrice: %v", err)
+	}
+	report := &domain.PriceMessage{
+		MessageID: "report",
+		Feed:      "ETH/USD",
+		Round:     1,
+		Price:     price,
+		Publisher: "publisher",
+		CreatedAt: time.Now().Unix(),
+	}
+
+	for _, scheme := range []domain.SignatureScheme{domain.SchemeSecp256k1EIP191, domain.SchemeSecp256k1EIP712} {
+		t.Run(string(scheme), func(t *testing.T) {
+			for i := 0; i < 8; i++ {
+				key, _, err := crypto.GenerateSecp256k1Key(nil)
+				if err != nil {
+					t.Fatalf("GenerateSecp256k1Key: %v", err)
+				}
+				signature, err := signReport(key, scheme, report)
+				if err != nil {
+					t.Fatalf("signReport: %v", err)
+				}
+				// [r || s || v] with v in {27, 28}, as expected by ecrecover
+				if len(signature) != 65 || (signature[64] != 27 && signature[64] != 28) {
+					t.Fatalf("signature %x is not [r || s || v] with v in {27, 28}", signature)
+				}
+				if ok, err := verifyReport(key.GetPublic(), scheme, report, signature); err != nil || !ok {
+					t.Errorf("verifyReport = %v, %v, want true", ok, err)
+				}
+			}
+		})
+	}
+
+	// A signature is only valid in the domain it was made in
+	key, _, err := crypto.GenerateSecp256k1Key(nil)
+	if err != nil {
+		t.Fatalf("GenerateSecp256k1Key: %v", err)
+	}
+	digest := ethereumDigest(domain.SchemeSecp256k1EIP712, report)
+	signature, err := signReport(key, domain.SchemeSecp256k1EIP712, report)
+	if err != nil {
+		t.Fatalf("signReport: %v", err)
+	}
+	if err := SetEIP712Domain(EIP712Domain{ChainID: 10, VerifyingContract: "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"}); err != nil {
+		t.Fatalf("SetEIP712Domain: %v", err)
+	}
+	if bytes.Equal(ethereumDigest(domain.SchemeSecp256k1EIP712, report), digest) {
+		t.Errorf("the digest of the report does not depend on the chain ID")
+	}
+	if ok, _ := verifyReport(key.GetPublic(), domain.SchemeSecp256k1EIP712, report, signature); ok {
+		t.Errorf("verifyReport accepted a signature of another domain")
+	}
+}
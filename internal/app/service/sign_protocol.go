@@ -0,0 +1,234 @@
+package service
+
+import (
+	"chainlink-lite/internal/app/domain"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	log "github.com/sirupsen/logrus"
+)
+
+// SignProtocolID is the stream protocol the proposer of a report collects the signatures of the committee on
+const SignProtocolID = protocol.ID("/chainlink-lite/sign/1.0.0")
+
+// maxSignMessageSize bounds the size of a signing request or response read from a stream
+const maxSignMessageSize = 1 << 20
+
+// signStreamTimeout bounds the time a signing request may take on the stream
+const signStreamTimeout = 10 * time.Second
+
+// SignHandler signs the report proposed by the peer, or returns the reason it refuses to
+type SignHandler func(ctx context.Context, from peer.ID, report *domain.PriceMessage) (domain.SignatureShare, error)
+
+// SignProtocol collects the signatures of a report point to point, instead of republishing every partially signed copy
+// Signing requests of every feed share the protocol, and are answered by the handler of their feed
+type SignProtocol struct {
+	host host.Host
+	keys *KeyRegistry
+
+	mu       sync.RWMutex
+	handlers map[string]SignHandler // by feed
+}
+
+// NewSignProtocol creates the signing protocol of the host, accepting the signatures of the keys of the registry
+func NewSignProtocol(h host.Host, keys *KeyRegistry) *SignProtocol {
+	return &SignProtocol{host: h, keys: keys, handlers: make(map[string]SignHandler)}
+}
+
+// Handle answers the signing requests of the reports of the feed with the handler
+func (s *SignProtocol) Handle(feed string, handler SignHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[feed] = handler
+}
+
+// handler returns the handler of the feed
+func (s *SignProtocol) handler(feed string) (SignHandler, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	handler, ok := s.handlers[feed]
+	return handler, ok
+}
+
+// Serve answers the signing requests of the peers with the handler of the feed of their report
+func (s *SignProtocol) Serve(ctx context.Context) {
+	s.host.SetStreamHandler(SignProtocolID, func(stream network.Stream) {
+		defer stream.Close()
+		from := stream.Conn().RemotePeer()
+		ctx, cancel := context.WithTimeout(ctx, signStreamTimeout)
+		defer cancel()
+		if deadline, ok := ctx.Deadline(); ok {
+			_ = stream.SetDeadline(deadline)
+		}
+
+		var request domain.SignRequest
+		if err := json.NewDecoder(io.LimitReader(stream, maxSignMessageSize)).Decode(&request); err != nil {
+			log.Debugf("Failed to decode signing request from %s: %v", from, err)
+			_ = stream.Reset()
+			return
+		}
+
+		var response domain.SignResponse
+		if handler, ok := s.handler(request.Report.Feed); !ok {
+			response.Error = fmt.Errorf("%w: %s is not served", domain.ErrUnsupportedFeed, request.Report.Feed).Error()
+		} else if share, err := handler(ctx, from, &request.Report); err != nil {
+			response.Error = err.Error()
+		} else {
+			response.Share = &share
+		}
+		if err := json.NewEncoder(stream).Encode(response); err != nil {
+			log.Debugf("Failed to answer signing request from %s: %v", from, err)
+			_ = stream.Reset()
+		}
+	})
+}
+
+// Close stops answering signing requests
+func (s *SignProtocol) Close() {
+	s.host.RemoveStreamHandler(SignProtocolID)
+}
+
+// Request asks the peer to co-sign the report, and returns its signature share
+func (s *SignProtocol) Request(ctx context.Context, p peer.ID, report *domain.PriceMessage) (domain.SignatureShare, error) {
+	stream, err := s.host.NewStream(ctx, p, SignProtocolID)
+	if err != nil {
+		return domain.SignatureShare{}, err
+	}
+	defer stream.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = stream.SetDeadline(deadline)
+	}
+
+	if err := json.NewEncoder(stream).Encode(domain.SignRequest{Report: *report}); err != nil {
+		_ = stream.Reset()
+		return domain.SignatureShare{}, err
+	}
+	if err := stream.CloseWrite(); err != nil {
+		_ = stream.Reset()
+		return domain.SignatureShare{}, err
+	}
+
+	var response domain.SignResponse
+	if err := json.NewDecoder(io.LimitReader(stream, maxSignMessageSize)).Decode(&response); err != nil {
+		_ = stream.Reset()
+		return domain.SignatureShare{}, err
+	}
+	if response.Error != "" {
+		return domain.SignatureShare{}, fmt.Errorf("%w: %s", domain.ErrSigningRefused, response.Error)
+	}
+	if response.Share == nil {
+		return domain.SignatureShare{}, errors.New("empty signing response")
+	}
+	return *response.Share, nil
+}
+
+// Peers returns the subscribers of the topic of a feed running the node of a key that may sign reports,
+// the committee members with a committee
+func (s *SignProtocol) Peers(subscribers []peer.ID) []peer.ID {
+	var peers []peer.ID
+	for _, p := range subscribers {
+		if s.keys.IsKnownHost(p.String()) {
+			peers = append(peers, p)
+		}
+	}
+	return peers
+}
+
+// Collect asks the peers of the subscribers of the topic of the feed to co-sign the report, and merges their verified
+// shares into the pending report until threshold signatures are collected, every peer answered, or the context is done
+// Returns the merged report
+func (s *SignProtocol) Collect(ctx context.Context, report *domain.PriceMessage, subscribers []peer.ID, threshold int,
+	pending *PendingReports) (*domain.PriceMessage, error) {
+	merged, _, err := pending.Merge(report)
+	if err != nil {
+		return nil, err
+	}
+	if merged.SignatureCount() >= threshold {
+		return merged, nil
+	}
+
+	peers := s.Peers(subscribers)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	shares := make(chan *domain.PriceMessage, len(peers))
+	for _, p := range peers {
+		go func(p peer.ID) {
+			share, err := s.Request(ctx, p, report)
+			if err != nil {
+				log.Debugf("No signature of %s from %s: %v", report.MessageID, p, err)
+				shares <- nil
+				return
+			}
+			signed, err := VerifyShare(report, share, s.keys)
+			if err != nil {
+				log.Warnf("Invalid signature of %s from %s: %v", report.MessageID, p, err)
+				shares <- nil
+				return
+			}
+			shares <- signed
+		}(p)
+	}
+
+	for range peers {
+		select {
+		case <-ctx.Done():
+			return merged, nil
+		case signed := <-shares:
+			if signed == nil {
+				continue
+			}
+			if merged, _, err = pending.Merge(signed); err != nil {
+				return nil, err
+			}
+			if merged.SignatureCount() >= threshold {
+				return merged, nil
+			}
+		}
+	}
+	return merged, nil
+}
+
+// VerifyShare verifies the signature share of a node over the report
+// Returns a copy of the report carrying only the share, to merge into the pending report
+func VerifyShare(report *domain.PriceMessage, share domain.SignatureShare, keys *KeyRegistry) (*domain.PriceMessage, error) {
+	signed := cloneReport(report)
+	signed.Signers, signed.PublicKeys, signed.Signatures, signed.Aggregate = nil, nil, nil, nil
+
+	if share.Aggregate != nil {
+		committee := keys.Committee()
+		if committee == nil {
+			return nil, errors.New("aggregate signatures require a committee")
+		}
+		signed.Aggregate = share.Aggregate
+		signers, err := VerifyAggregateSignature(signed, committee)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range signers {
+			if keys.IsRevoked(m.PeerID) {
+				return nil, fmt.Errorf("%w: signer %s", domain.ErrKeyRevoked, m.PeerID)
+			}
+		}
+		return signed, nil
+	}
+
+	if !keys.IsActive(share.Signer, share.PublicKey) {
+		return nil, fmt.Errorf("%w: %s", domain.ErrNotCommitteeMember, share.Signer)
+	}
+	if err := VerifyReportSignature(signed, share.Signer, share.PublicKey, share.Signature); err != nil {
+		return nil, err
+	}
+	signed.Signers = []string{share.Signer}
+	signed.PublicKeys = []string{share.PublicKey}
+	signed.Signatures = []string{share.Signature}
+	return signed, nil
+}
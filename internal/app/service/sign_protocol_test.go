@@ -0,0 +1,45 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"chainlink-lite/internal/app/domain"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+func TestSignProtocolPeers(t *testing.T) {
+	member, _ := testMember(t, "member")
+	remote, _ := testMember(t, "remote")
+	host, _ := testMember(t, "host of remote")
+	remote.HostPeerID = host.PeerID
+	outsider, _ := testMember(t, "outsider")
+	ids := make(map[string]peer.ID)
+	for _, m := range []domain.Member{member, host, outsider} {
+		id, err := peer.Decode(m.PeerID)
+		if err != nil {
+			t.Fatalf("decode %s: %v", m.PeerID, err)
+		}
+		ids[m.Name] = id
+	}
+	subscribers := []peer.ID{ids["member"], ids["host of remote"], ids["outsider"]}
+
+	// Without a committee, every subscriber of the topic is asked to sign
+	signing := NewSignProtocol(nil, NewKeyRegistry(nil, domain.RevocationList{}, time.Hour, time.Minute))
+	if got := signing.Peers(subscribers); !reflect.DeepEqual(got, subscribers) {
+		t.Errorf("Peers without a committee = %v, want %v", got, subscribers)
+	}
+	if got := signing.Peers(nil); len(got) != 0 {
+		t.Errorf("Peers without subscribers = %v, want none", got)
+	}
+
+	// With a committee, only the hosts of the members are
+	committee := &domain.Committee{Version: 1, Members: []domain.Member{member, remote}}
+	signing = NewSignProtocol(nil, NewKeyRegistry(committee, domain.RevocationList{}, time.Hour, time.Minute))
+	want := []peer.ID{ids["member"], ids["host of remote"]}
+	if got := signing.Peers(subscribers); !reflect.DeepEqual(got, want) {
+		t.Errorf("Peers with a committee = %v, want %v", got, want)
+	}
+}
@@ -1,30 +1,82 @@
 package service
 
 import (
+	"chainlink-lite/internal/app/domain"
+	"context"
 	"encoding/hex"
+	"fmt"
 
 	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
 )
 
+// SignerService is the local domain.Signer, holding the private key in memory
 type SignerService struct {
-	key crypto.PrivKey
+	key       crypto.PrivKey
+	scheme    domain.SignatureScheme
+	id        string
+	publicKey string
 }
 
-func NewSignerService() (*SignerService, error) {
-	key, _, err := crypto.GenerateKeyPair(crypto.ECDSA, -1)
+var _ domain.Signer = (*SignerService)(nil)
+
+// NewSignerService creates a signer for the node key, usually loaded from the keystore
+// The key type must match the signature scheme
+func NewSignerService(key crypto.PrivKey, scheme domain.SignatureScheme) (*SignerService, error) {
+	if err := checkKeyType(key.Type(), scheme); err != nil {
+		return nil, err
+	}
+
+	id, err := peer.IDFromPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, err := EncodePublicKey(key.GetPublic())
 	if err != nil {
 		return nil, err
 	}
-	return &SignerService{key: key}, nil
+	return &SignerService{key: key, scheme: scheme, id: id.String(), publicKey: publicKey}, nil
 }
 
-// SignMessage signs a message with the private key
-func (s *SignerService) SignMessage(message string) (string, error) {
-	signature, err := s.key.Sign([]byte(message))
+// SignReport signs the report with the private key
+// Returns the hex encoded signature tagged with the signature scheme
+func (s *SignerService) SignReport(_ context.Context, report *domain.PriceMessage) (string, error) {
+	signature, err := signReport(s.key, s.scheme, report)
 	if err != nil {
 		return "", err
 	}
-	return hex.EncodeToString(signature), nil
+	return domain.FormatSignature(s.scheme, hex.EncodeToString(signature)), nil
+}
+
+// SignObservation signs the observation with the private key
+// Returns the hex encoded signature tagged with the signature scheme
+func (s *SignerService) SignObservation(_ context.Context, observation *domain.Observation) (string, error) {
+	signature, err := signPayload(s.key, s.scheme, observation.SigningPayload())
+	if err != nil {
+		return "", err
+	}
+	return domain.FormatSignature(s.scheme, hex.EncodeToString(signature)), nil
+}
+
+// SignCommitment signs the commitment with the private key
+// Returns the hex encoded signature tagged with the signature scheme
+func (s *SignerService) SignCommitment(_ context.Context, commitment *domain.Commitment) (string, error) {
+	signature, err := signPayload(s.key, s.scheme, commitment.SigningPayload())
+	if err != nil {
+		return "", err
+	}
+	return domain.FormatSignature(s.scheme, hex.EncodeToString(signature)), nil
+}
+
+// ID returns the node ID derived from the private key
+func (s *SignerService) ID() string {
+	return s.id
+}
+
+// PublicKey returns the hex encoded public key, as carried in PriceMessage.PublicKeys
+func (s *SignerService) PublicKey() string {
+	return s.publicKey
 }
 
 func (s *SignerService) GetPublicKey() crypto.PubKey {
@@ -35,11 +87,92 @@ func (s *SignerService) GetPrivateKey() crypto.PrivKey {
 	return s.key
 }
 
-// VerifySignature verifies the signature of a message
-func VerifySignature(message string, hexSignature string, pub crypto.PubKey) (bool, error) {
+// VerifySignature verifies a signature tagged with its scheme over the report
+func VerifySignature(report *domain.PriceMessage, signature string, pub crypto.PubKey) (bool, error) {
+	scheme, hexSignature, err := domain.ParseSignature(signature)
+	if err != nil {
+		return false, err
+	}
 	sigBytes, err := hex.DecodeString(hexSignature)
 	if err != nil {
 		return false, err
 	}
-	return pub.Verify([]byte(message), sigBytes)
+	return verifyReport(pub, scheme, report, sigBytes)
+}
+
+// EncodePublicKey hex encodes the protobuf serialization of a public key
+func EncodePublicKey(pub crypto.PubKey) (string, error) {
+	data, err := crypto.MarshalPublicKey(pub)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(data), nil
+}
+
+// DecodePublicKey decodes a public key encoded with EncodePublicKey
+func DecodePublicKey(hexKey string) (crypto.PubKey, error) {
+	data, err := hex.DecodeString(hexKey)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.UnmarshalPublicKey(data)
+}
+
+// VerifyMessageSignatures verifies every signature of the message against the public key of its signer,
+// under the scheme the signature is tagged with.
+// A signature is only attributable if the public key it is listed with derives the signer's node ID.
+func VerifyMessageSignatures(priceMsg *domain.PriceMessage) error {
+	if len(priceMsg.Signers) == 0 {
+		return fmt.Errorf("%w: message has no signatures", domain.ErrUnattributableSignature)
+	}
+	if len(priceMsg.Signatures) != len(priceMsg.Signers) || len(priceMsg.PublicKeys) != len(priceMsg.Signers) {
+		return fmt.Errorf("%w: %d signers, %d public keys, %d signatures", domain.ErrUnattributableSignature,
+			len(priceMsg.Signers), len(priceMsg.PublicKeys), len(priceMsg.Signatures))
+	}
+
+	seenSigners := make(map[string]struct{}, len(priceMsg.Signers))
+	seenSignatures := make(map[string]struct{}, len(priceMsg.Signatures))
+	for i, signer := range priceMsg.Signers {
+		signature := priceMsg.Signatures[i]
+		if _, ok := seenSigners[signer]; ok {
+			return fmt.Errorf("%w: signer %s", domain.ErrDuplicateSignature, signer)
+		}
+		if _, ok := seenSignatures[signature]; ok {
+			return fmt.Errorf("%w: signature %s", domain.ErrDuplicateSignature, signature)
+		}
+		seenSigners[signer] = struct{}{}
+		seenSignatures[signature] = struct{}{}
+
+		if err := VerifyReportSignature(priceMsg, signer, priceMsg.PublicKeys[i], signature); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// VerifyReportSignature verifies a single signature over the report, and that the public key derives the signer's node ID
+func VerifyReportSignature(report *domain.PriceMessage, signer string, publicKey string, signature string) error {
+	pub, err := VerifyIdentity(signer, publicKey)
+	if err != nil {
+		return err
+	}
+
+	valid, err := VerifySignature(report, signature, pub)
+	if err != nil || !valid {
+		return fmt.Errorf("%w: signer %s", domain.ErrInvalidSignature, signer)
+	}
+	return nil
+}
+
+// VerifyIdentity decodes the public key and checks that it derives the signer's node ID
+func VerifyIdentity(signer string, publicKey string) (crypto.PubKey, error) {
+	pub, err := DecodePublicKey(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w: signer %s: %v", domain.ErrUnattributableSignature, signer, err)
+	}
+	id, err := peer.IDFromPublicKey(pub)
+	if err != nil || id.String() != signer {
+		return nil, fmt.Errorf("%w: public key does not match signer %s", domain.ErrUnattributableSignature, signer)
+	}
+	return pub, nil
 }
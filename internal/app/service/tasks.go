@@ -0,0 +1,94 @@
+package service
+
+import (
+	"chainlink-lite/internal/app/domain"
+	"context"
+	"fmt"
+)
+
+// PipelineTasks returns the factories of the tasks computing on prices, by task type
+func PipelineTasks() map[string]TaskFactory {
+	return map[string]TaskFactory{
+		domain.TaskMultiply: NewMultiplyTask,
+		domain.TaskMedian:   NewMedianTask,
+	}
+}
+
+// MultiplyTask multiplies the price of its input by a decimal, exactly
+type MultiplyTask struct {
+	times domain.Price
+}
+
+// NewMultiplyTask creates the multiply task of the spec, its times parameter is the decimal the price is multiplied by
+func NewMultiplyTask(spec domain.TaskSpec) (Task, error) {
+	var params struct {
+		Times string `yaml:"times"`
+	}
+	if err := DecodeTaskParams(spec, &params); err != nil {
+		return nil, err
+	}
+	if len(spec.Inputs) != 1 {
+		return nil, fmt.Errorf("multiply takes a single input")
+	}
+	times, err := domain.ParseDecimal(params.Times)
+	if err != nil || times.Sign() <= 0 {
+		return nil, fmt.Errorf("times %q is not a positive decimal", params.Times)
+	}
+	return &MultiplyTask{times: times}, nil
+}
+
+// Run returns the price of the input multiplied by the decimal
+func (t *MultiplyTask) Run(_ context.Context, _ TaskVars, inputs []TaskResult) (interface{}, error) {
+	values, err := TaskInputs(inputs, 1)
+	if err != nil {
+		return nil, err
+	}
+	price, ok := values[0].(domain.Price)
+	if !ok {
+		return nil, fmt.Errorf("%w: input is not a price", domain.ErrInvalidPrice)
+	}
+	return price.Mul(t.times)
+}
+
+// MedianTask returns the median of the prices of its inputs, once a minimum number of them succeeded
+type MedianTask struct {
+	minInputs int
+}
+
+// NewMedianTask creates the median task of the spec
+// Its min_inputs parameter is the number of inputs that must succeed, a majority of the inputs by default
+func NewMedianTask(spec domain.TaskSpec) (Task, error) {
+	params := struct {
+		MinInputs int `yaml:"min_inputs"`
+	}{MinInputs: len(spec.Inputs)/2 + 1}
+	if err := DecodeTaskParams(spec, &params); err != nil {
+		return nil, err
+	}
+	if len(spec.Inputs) == 0 {
+		return nil, fmt.Errorf("median takes at least one input")
+	}
+	if params.MinInputs < 1 || params.MinInputs > len(spec.Inputs) {
+		return nil, fmt.Errorf("min_inputs %d out of %d inputs", params.MinInputs, len(spec.Inputs))
+	}
+	return &MedianTask{minInputs: params.MinInputs}, nil
+}
+
+// Run returns the median of the prices of the inputs that succeeded, equal prices being ordered by input
+func (t *MedianTask) Run(_ context.Context, _ TaskVars, inputs []TaskResult) (interface{}, error) {
+	prices := make([]domain.Price, 0, len(inputs))
+	for _, result := range inputs {
+		if result.Err != nil {
+			continue
+		}
+		price, ok := result.Value.(domain.Price)
+		if !ok || price.Sign() <= 0 {
+			continue
+		}
+		prices = append(prices, price)
+	}
+	if len(prices) < t.minInputs {
+		return nil, fmt.Errorf("%w: %d of %d inputs returned a price, %d required",
+			domain.ErrFailedToFetchPrice, len(prices), len(inputs), t.minInputs)
+	}
+	return prices[domain.MedianIndex(prices)], nil
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"chainlink-lite/internal/app/domain"
+)
+
+// priceResult returns the result of a task returning the price
+func priceResult(t *testing.T, s string) TaskResult {
+	t.Helper()
+	price, err := domain.ParsePrice(s)
+	if err != nil {
+		t.Fatalf("ParsePrice(%q): %v", s, err)
+	}
+	return TaskResult{Value: price}
+}
+
+func TestMedianTask(t *testing.T) {
+	failed := TaskResult{Err: errors.New("source down")}
+	tests := []struct {
+		name      string
+		minInputs int
+		inputs    []TaskResult
+		want      string
+	}{
+		{name: "odd", inputs: []TaskResult{priceResult(t, "3"), priceResult(t, "1"), priceResult(t, "2")}, want: "2"},
+		{name: "even takes the upper price", inputs: []TaskResult{priceResult(t, "4"), priceResult(t, "1"), priceResult(t, "3"), priceResult(t, "2")}, want: "3"},
+		{name: "equal prices in input order", inputs: []TaskResult{priceResult(t, "2.00"), priceResult(t, "2.0"), priceResult(t, "1")}, want: "2.00"},
+		{name: "failed input skipped", minInputs: 2, inputs: []TaskResult{priceResult(t, "1"), failed, priceResult(t, "3")}, want: "3"},
+		{name: "not a price skipped", minInputs: 2, inputs: []TaskResult{priceResult(t, "1"), {Value: "2"}, priceResult(t, "3")}, want: "3"},
+		{name: "zero price skipped", minInputs: 2, inputs: []TaskResult{priceResult(t, "0"), priceResult(t, "1"), priceResult(t, "3")}, want: "3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := domain.TaskSpec{ID: "median", Type: domain.TaskMedian, Inputs: make([]string, len(tt.inputs))}
+			if tt.minInputs > 0 {
+				spec.Params = map[string]interface{}{"min_inputs": tt.minInputs}
+			}
+			task, err := NewMedianTask(spec)
+			if err != nil {
+				t.Fatalf("NewMedianTask: %v", err)
+			}
+			value, err := task.Run(context.Background(), TaskVars{}, tt.inputs)
+			if err != nil {
+				t.Fatalf("Run: %v", err)
+			}
+			if price := value.(domain.Price); price.String() != tt.want {
+				t.Errorf("Run = %s, want %s", price, tt.want)
+			}
+		})
+	}
+}
+
+func TestMedianTaskMinInputs(t *testing.T) {
+	// A majority of the inputs must return a price by default
+	task, err := NewMedianTask(domain.TaskSpec{ID: "median", Inputs: []string{"a", "b", "c"}})
+	if err != nil {
+		t.Fatalf("NewMedianTask: %v", err)
+	}
+	failed := TaskResult{Err: errors.New("source down")}
+	_, err = task.Run(context.Background(), TaskVars{}, []TaskResult{priceResult(t, "1"), failed, failed})
+	if !errors.Is(err, domain.ErrFailedToFetchPrice) {
+		t.Errorf("Run with 1 of 3 inputs = %v, want %v", err, domain.ErrFailedToFetchPrice)
+	}
+
+	for _, spec := range []domain.TaskSpec{
+		{ID: "median"},
+		{ID: "median", Inputs: []string{"a", "b"}, Params: map[string]interface{}{"min_inputs": 3}},
+		{ID: "median", Inputs: []string{"a", "b"}, Params: map[string]interface{}{"min_inputs": 0}},
+		{ID: "median", Inputs: []string{"a", "b"}, Params: map[string]interface{}{"min_input": 1}},
+	} {
+		if _, err := NewMedianTask(spec); err == nil {
+			t.Errorf("NewMedianTask(%v) succeeded", spec)
+		}
+	}
+}
+
+func TestMultiplyTask(t *testing.T) {
+	tests := []struct {
+		times string
+		in    string
+		want  string
+	}{
+		{times: "1", in: "3456.78", want: "3456.78"},
+		{times: "0.01", in: "345678", want: "3456.78"},
+		{times: "1.5", in: "0.3", want: "0.45"},
+		{times: "1e3", in: "0.001", want: "1.000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.times, func(t *testing.T) {
+			task, err := NewMultiplyTask(domain.TaskSpec{ID: "multiply", Inputs: []string{"a"}, Params: map[string]interface{}{"times": tt.times}})
+			if err != nil {
+				t.Fatalf("NewMultiplyTask: %v", err)
+			}
+			value, err := task.Run(context.Background(), TaskVars{}, []TaskResult{priceResult(t, tt.in)})
+			if err != nil {
+				t.Fatalf("Run: %v", err)
+			}
+			price := value.(domain.Price)
+			if want := priceResult(t, tt.want).Value.(domain.Price); price.Cmp(want) != 0 {
+				t.Errorf("Run = %s, want %s", price, tt.want)
+			}
+		})
+	}
+}
+
+func TestMultiplyTaskRejectsInvalid(t *testing.T) {
+	// Numbers are taken as decimals as well
+	if _, err := NewMultiplyTask(domain.TaskSpec{ID: "multiply", Inputs: []string{"a"}, Params: map[string]interface{}{"times": 2}}); err != nil {
+		t.Errorf("NewMultiplyTask with a number: %v", err)
+	}
+	for _, times := range []interface{}{"", "0", "-1", "abc"} {
+		spec := domain.TaskSpec{ID: "multiply", Inputs: []string{"a"}, Params: map[string]interface{}{"times": times}}
+		if _, err := NewMultiplyTask(spec); err == nil {
+			t.Errorf("NewMultiplyTask with times %q succeeded", times)
+		}
+	}
+	if _, err := NewMultiplyTask(domain.TaskSpec{ID: "multiply", Inputs: []string{"a", "b"}, Params: map[string]interface{}{"times": "1"}}); err == nil {
+		t.Errorf("NewMultiplyTask with two inputs succeeded")
+	}
+
+	task, err := NewMultiplyTask(domain.TaskSpec{ID: "multiply", Inputs: []string{"a"}, Params: map[string]interface{}{"times": "2"}})
+	if err != nil {
+		t.Fatalf("NewMultiplyTask: %v", err)
+	}
+	errDown := errors.New("source down")
+	if _, err := task.Run(context.Background(), TaskVars{}, []TaskResult{{Err: errDown}}); !errors.Is(err, errDown) {
+		t.Errorf("Run with a failed input = %v, want %v", err, errDown)
+	}
+	if _, err := task.Run(context.Background(), TaskVars{}, []TaskResult{{Value: "2"}}); !errors.Is(err, domain.ErrInvalidPrice) {
+		t.Errorf("Run with a string input = %v, want %v", err, domain.ErrInvalidPrice)
+	}
+}
@@ -0,0 +1,449 @@
+package service
+
+import (
+	"chainlink-lite/internal/app/domain"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	"github.com/libp2p/go-libp2p/core/peer"
+	log "github.com/sirupsen/logrus"
+)
+
+// Reasons a message is rejected or ignored by the topic validator
+const (
+	RejectMalformed   = "malformed"
+	RejectInvalid     = "invalid"
+	RejectNotMember   = "not_member"
+	RejectSignature   = "bad_signature"
+	RejectRotation    = "bad_rotation"
+	RejectReport      = "bad_report"
+	RejectNotLeader   = "not_leader"
+	RejectReveal      = "bad_reveal"
+	RejectFeed        = "wrong_feed"
+	RejectQuorum      = "below_quorum"
+	IgnoreStale       = "stale"
+	IgnoreRevoked     = "revoked"
+	IgnoreRotation    = "rotation_conflict"
+	IgnoreObserved    = "already_observed"
+	IgnoreEarly       = "early"
+	IgnoreCommitted   = "already_committed"
+	IgnoreLate        = "late_commitment"
+	IgnoreUncommitted = "uncommitted"
+	IgnoreRound       = "wrong_round"
+	IgnoreEarlyReveal = "early_reveal"
+)
+
+// MessageValidator is the GossipSub topic validator of price messages
+// It runs before a message is delivered or relayed, so bad messages never reach the rest of the mesh,
+// and rejected messages penalize the peer that forwarded them
+type MessageValidator struct {
+	keys            *KeyRegistry
+	feed            string
+	committee       *domain.Committee
+	schedule        *Schedule
+	observations    *ObservationPool
+	quorum          *QuorumTracker
+	decimals        uint8
+	minObservations int
+	maxAge          time.Duration
+
+	mu     sync.Mutex
+	counts map[string]uint64
+}
+
+// NewMessageValidator creates the validator of the topics of the feed, enforcing the keys of the registry, the leader schedule,
+// the write quorum and the decimals of the prices of the feed, the quorum of observations and the maximum message age
+// Messages of any other feed are rejected, so they are never checked against the schedule and quorum of this feed
+// Accepted observations and reports are recorded in the observation pool
+func NewMessageValidator(keys *KeyRegistry, feed string, schedule *Schedule, observations *ObservationPool, quorum *QuorumTracker,
+	decimals uint8, minObservations int, maxAge time.Duration) *MessageValidator {
+	return &MessageValidator{
+		keys:            keys,
+		feed:            feed,
+		committee:       keys.Committee(),
+		schedule:        schedule,
+		observations:    observations,
+		quorum:          quorum,
+		decimals:        decimals,
+		minObservations: minObservations,
+		maxAge:          maxAge,
+		counts:          make(map[string]uint64),
+	}
+}
+
+// Validate decodes and checks the message
+// Leaders only announce reports signed by the write quorum, so reports with fewer active signers are rejected
+// The decoded message is stored in msg.ValidatorData for the subscriber
+func (v *MessageValidator) Validate(_ context.Context, from peer.ID, msg *pubsub.Message) pubsub.ValidationResult {
+	var priceMsg domain.PriceMessage
+	if err := json.Unmarshal(msg.Data, &priceMsg); err != nil {
+		return v.reject(RejectMalformed, from, &priceMsg, err)
+	}
+
+	switch result, reason, err := v.checkReport(&priceMsg); result {
+	case pubsub.ValidationAccept:
+		if threshold := v.quorum.Threshold(); priceMsg.SignatureCount() < threshold {
+			return v.reject(RejectQuorum, from, &priceMsg, fmt.Errorf("%w: %d signatures, %d required",
+				domain.ErrInsufficientSignatures, priceMsg.SignatureCount(), threshold))
+		}
+		v.accept(msg, &priceMsg)
+		return result
+	case pubsub.ValidationIgnore:
+		return v.ignore(reason, from, &priceMsg)
+	default:
+		return v.reject(reason, from, &priceMsg, err)
+	}
+}
+
+// ValidateProposal checks a report the node is asked to co-sign, as the reports relayed on the topic are checked
+func (v *MessageValidator) ValidateProposal(from peer.ID, report *domain.PriceMessage) error {
+	switch result, reason, err := v.checkReport(report); result {
+	case pubsub.ValidationAccept:
+		return nil
+	case pubsub.ValidationIgnore:
+		v.ignore(reason, from, report)
+		return err
+	default:
+		v.reject(reason, from, report, err)
+		return err
+	}
+}
+
+// checkReport checks the decoded report
+// Returns the result, and the reason and the error if the report is not accepted
+func (v *MessageValidator) checkReport(priceMsg *domain.PriceMessage) (pubsub.ValidationResult, string, error) {
+	if err := ValidateMessage(*priceMsg); err != nil {
+		return pubsub.ValidationReject, RejectInvalid, err
+	}
+	if err := v.checkFeed(priceMsg.Feed); err != nil {
+		return pubsub.ValidationReject, RejectFeed, err
+	}
+	if err := v.checkPrices(priceMsg); err != nil {
+		return pubsub.ValidationReject, RejectInvalid, err
+	}
+
+	// Stale messages may come from honest but slow peers, so they are dropped without penalty
+	age := time.Since(time.Unix(priceMsg.CreatedAt, 0))
+	if v.maxAge > 0 && (age > v.maxAge || age < -v.maxAge) {
+		return pubsub.ValidationIgnore, IgnoreStale, fmt.Errorf("message created %s ago", age.Round(time.Second))
+	}
+
+	// Only the leader of the epoch proposes, once the leaders of the previous epochs timed out
+	if err := v.schedule.CheckProposal(priceMsg, time.Now()); err != nil {
+		if errors.Is(err, domain.ErrEarlyProposal) {
+			return pubsub.ValidationIgnore, IgnoreEarly, err
+		}
+		return pubsub.ValidationReject, RejectNotLeader, err
+	}
+
+	// The answer must be the median of a quorum of signed observations
+	if err := VerifyReportObservations(priceMsg, v.minObservations, v.keys); err != nil {
+		reason := RejectReport
+		if errors.Is(err, domain.ErrInvalidSignature) || errors.Is(err, domain.ErrUnattributableSignature) {
+			reason = RejectSignature
+		} else if errors.Is(err, domain.ErrNotCommitteeMember) {
+			reason = RejectNotMember
+		}
+		return pubsub.ValidationReject, reason, err
+	}
+
+	if priceMsg.Aggregate != nil {
+		return v.checkAggregate(priceMsg)
+	}
+
+	// Collapse duplicate signers so a node is never counted twice towards the threshold
+	if removed := priceMsg.CollapseDuplicateSigners(); removed > 0 {
+		log.Debugf("Collapsed %d duplicate signatures on message %s", removed, priceMsg.MessageID)
+	}
+
+	// Revocations may not have reached every peer yet, so revoked publishers are dropped without penalty
+	if v.keys.IsRevoked(priceMsg.Publisher) {
+		return pubsub.ValidationIgnore, IgnoreRevoked, fmt.Errorf("%w: publisher %s", domain.ErrKeyRevoked, priceMsg.Publisher)
+	}
+	if !v.keys.IsKnown(priceMsg.Publisher) {
+		return pubsub.ValidationReject, RejectNotMember, domain.ErrNotCommitteeMember
+	}
+
+	// Ignore signatures of keys that are not members, revoked or retired,
+	// and count the old and new keys of a rotated identity only once
+	owners := make(map[string]struct{}, len(priceMsg.Signers))
+	removed := priceMsg.RetainSigners(func(signer string, publicKey string) bool {
+		if !v.keys.IsActive(signer, publicKey) {
+			return false
+		}
+		owner := v.keys.Owner(signer)
+		if _, ok := owners[owner]; ok {
+			return false
+		}
+		owners[owner] = struct{}{}
+		return true
+	})
+	if removed > 0 {
+		log.Debugf("Ignoring %d signatures from inactive keys on message %s", removed, priceMsg.MessageID)
+	}
+
+	// The publisher's own signature vouches that the member did publish the message
+	if v.committee != nil && priceMsg.SignerIndex(priceMsg.Publisher) < 0 {
+		return pubsub.ValidationReject, RejectSignature, domain.ErrUnattributableSignature
+	}
+
+	// Verify every signature against the public key of its signer
+	if err := VerifyMessageSignatures(priceMsg); err != nil {
+		reason := RejectSignature
+		if errors.Is(err, domain.ErrDuplicateSignature) {
+			reason = RejectInvalid
+		}
+		return pubsub.ValidationReject, reason, err
+	}
+	return pubsub.ValidationAccept, "", nil
+}
+
+// checkFeed checks that the message belongs to the feed of the topic
+func (v *MessageValidator) checkFeed(feed string) error {
+	if feed != v.feed {
+		return fmt.Errorf("%w: %s on the topic of %s", domain.ErrWrongFeed, feed, v.feed)
+	}
+	return nil
+}
+
+// checkPrices checks that the answer and the observations of the report are prices with the decimals of the feed
+func (v *MessageValidator) checkPrices(priceMsg *domain.PriceMessage) error {
+	if err := priceMsg.Price.Check(v.decimals); err != nil {
+		return err
+	}
+	for _, o := range priceMsg.Observations {
+		if err := o.Price.Check(v.decimals); err != nil {
+			return fmt.Errorf("observation of %s: %w", o.Observer, err)
+		}
+	}
+	return nil
+}
+
+// checkAggregate checks a message carrying a BLS signature aggregated over the committee
+func (v *MessageValidator) checkAggregate(priceMsg *domain.PriceMessage) (pubsub.ValidationResult, string, error) {
+	if v.committee == nil {
+		return pubsub.ValidationReject, RejectNotMember, errors.New("aggregate signatures require a committee")
+	}
+	if len(priceMsg.Signers) > 0 || len(priceMsg.PublicKeys) > 0 || len(priceMsg.Signatures) > 0 {
+		return pubsub.ValidationReject, RejectInvalid, errors.New("message carries both individual and aggregate signatures")
+	}
+	if _, ok := v.committee.Member(priceMsg.Publisher); !ok {
+		return pubsub.ValidationReject, RejectNotMember, domain.ErrNotCommitteeMember
+	}
+
+	signers, err := VerifyAggregateSignature(priceMsg, v.committee)
+	if err != nil {
+		return pubsub.ValidationReject, RejectSignature, err
+	}
+	// A revoked signature can not be removed from the aggregate, so the whole message is dropped
+	for _, m := range signers {
+		if v.keys.IsRevoked(m.PeerID) {
+			return pubsub.ValidationIgnore, IgnoreRevoked, fmt.Errorf("%w: signer %s", domain.ErrKeyRevoked, m.PeerID)
+		}
+	}
+	for _, m := range signers {
+		if m.PeerID == priceMsg.Publisher {
+			return pubsub.ValidationAccept, "", nil
+		}
+	}
+	// The publisher's own signature vouches that the member did publish the message
+	return pubsub.ValidationReject, RejectSignature, domain.ErrUnattributableSignature
+}
+
+// ValidateObservation checks the signed observation of a node and adds it to the observation pool
+func (v *MessageValidator) ValidateObservation(_ context.Context, from peer.ID, msg *pubsub.Message) pubsub.ValidationResult {
+	var observation domain.Observation
+	if err := json.Unmarshal(msg.Data, &observation); err != nil {
+		return v.rejectObservation(RejectMalformed, from, &observation, err)
+	}
+	if err := validator.New().Struct(observation); err != nil {
+		return v.rejectObservation(RejectInvalid, from, &observation, err)
+	}
+	if err := v.checkFeed(observation.Feed); err != nil {
+		return v.rejectObservation(RejectFeed, from, &observation, err)
+	}
+	if err := observation.Price.Check(v.decimals); err != nil {
+		return v.rejectObservation(RejectInvalid, from, &observation, err)
+	}
+
+	age := time.Since(time.Unix(observation.CreatedAt, 0))
+	if v.maxAge > 0 && (age > v.maxAge || age < -v.maxAge) {
+		v.count(IgnoreStale)
+		return pubsub.ValidationIgnore
+	}
+	if !v.pooledRound(observation.Round) {
+		v.count(IgnoreRound)
+		return pubsub.ValidationIgnore
+	}
+	if v.keys.IsRevoked(observation.Observer) {
+		v.count(IgnoreRevoked)
+		return pubsub.ValidationIgnore
+	}
+	if !v.keys.IsActive(observation.Observer, observation.PublicKey) {
+		return v.rejectObservation(RejectNotMember, from, &observation, domain.ErrNotCommitteeMember)
+	}
+	if err := VerifyObservation(&observation); err != nil {
+		return v.rejectObservation(RejectSignature, from, &observation, err)
+	}
+
+	// Once rounds commit before they reveal, only observations matching the commitment of their observer are pooled,
+	// and only once the commit window closed, so a revealed price is not relayed to the nodes that did not commit yet
+	if v.schedule.CommitReveal() {
+		if time.Now().Before(v.schedule.RevealStart(observation.Round)) {
+			v.count(IgnoreEarlyReveal)
+			return pubsub.ValidationIgnore
+		}
+		commitment, ok := v.observations.CommitmentOf(observation.Feed, observation.Round, v.keys.Owner(observation.Observer))
+		if !ok {
+			v.count(IgnoreUncommitted)
+			return pubsub.ValidationIgnore
+		}
+		if domain.CommitmentDigest(observation) != commitment.Digest {
+			return v.rejectObservation(RejectReveal, from, &observation, domain.ErrCommitmentMismatch)
+		}
+	}
+
+	// Later observations of a node for the same round are dropped, but relayed observations are not the peer's fault
+	if !v.observations.Add(v.keys.Owner(observation.Observer), observation) {
+		v.count(IgnoreObserved)
+		return pubsub.ValidationIgnore
+	}
+	return pubsub.ValidationAccept
+}
+
+// pooledRound returns true if the round is the current or the next round of the feed, the only rounds pooled
+// The next round allows for the clock skew of the observer
+func (v *MessageValidator) pooledRound(round int64) bool {
+	current := v.schedule.Round(time.Now())
+	return round == current || round == current+1
+}
+
+// ValidateCommitment checks the signed commitment of a node to its observation and adds it to the observation pool
+// Commitments are only accepted until the commit window of their round closes, after which observations are revealed
+func (v *MessageValidator) ValidateCommitment(_ context.Context, from peer.ID, msg *pubsub.Message) pubsub.ValidationResult {
+	var commitment domain.Commitment
+	if err := json.Unmarshal(msg.Data, &commitment); err != nil {
+		return v.rejectCommitment(RejectMalformed, from, &commitment, err)
+	}
+	if err := validator.New().Struct(commitment); err != nil {
+		return v.rejectCommitment(RejectInvalid, from, &commitment, err)
+	}
+	if err := v.checkFeed(commitment.Feed); err != nil {
+		return v.rejectCommitment(RejectFeed, from, &commitment, err)
+	}
+
+	age := time.Since(time.Unix(commitment.CreatedAt, 0))
+	if v.maxAge > 0 && (age > v.maxAge || age < -v.maxAge) {
+		v.count(IgnoreStale)
+		return pubsub.ValidationIgnore
+	}
+	if !v.pooledRound(commitment.Round) {
+		v.count(IgnoreRound)
+		return pubsub.ValidationIgnore
+	}
+	if v.keys.IsRevoked(commitment.Observer) {
+		v.count(IgnoreRevoked)
+		return pubsub.ValidationIgnore
+	}
+	if !v.keys.IsActive(commitment.Observer, commitment.PublicKey) {
+		return v.rejectCommitment(RejectNotMember, from, &commitment, domain.ErrNotCommitteeMember)
+	}
+	if err := VerifyCommitment(&commitment); err != nil {
+		return v.rejectCommitment(RejectSignature, from, &commitment, err)
+	}
+
+	// A commitment made once observations may have been revealed proves nothing
+	if !time.Now().Before(v.schedule.RevealStart(commitment.Round)) {
+		v.count(IgnoreLate)
+		return pubsub.ValidationIgnore
+	}
+	if !v.observations.AddCommitment(v.keys.Owner(commitment.Observer), commitment) {
+		v.count(IgnoreCommitted)
+		return pubsub.ValidationIgnore
+	}
+	return pubsub.ValidationAccept
+}
+
+// ValidateRotation checks a key rotation announcement and accepts the successor key
+// Announcements are relayed again every time they are valid, so peers that joined later learn about them
+func (v *MessageValidator) ValidateRotation(_ context.Context, from peer.ID, msg *pubsub.Message) pubsub.ValidationResult {
+	var rotation domain.KeyRotation
+	if err := json.Unmarshal(msg.Data, &rotation); err != nil {
+		v.count(RejectMalformed)
+		log.Infof("Rejected key rotation from peer %s: %v", from, err)
+		return pubsub.ValidationReject
+	}
+	if err := VerifyRotation(rotation); err != nil {
+		count := v.count(RejectRotation)
+		log.Infof("Rejected key rotation from peer %s (%s, %d total): %v", from, RejectRotation, count, err)
+		return pubsub.ValidationReject
+	}
+
+	// A valid announcement may still conflict with what this node has seen, without the peer being at fault
+	if _, err := v.keys.AddRotation(rotation); err != nil {
+		reason := IgnoreRotation
+		if errors.Is(err, domain.ErrKeyRevoked) {
+			reason = IgnoreRevoked
+		}
+		count := v.count(reason)
+		log.Debugf("Ignored key rotation from %s to %s (%s, %d total): %v", rotation.OldPeerID, rotation.NewPeerID, reason, count, err)
+		return pubsub.ValidationIgnore
+	}
+	return pubsub.ValidationAccept
+}
+
+// Counts returns the number of rejected and ignored messages per reason
+func (v *MessageValidator) Counts() map[string]uint64 {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	counts := make(map[string]uint64, len(v.counts))
+	for reason, count := range v.counts {
+		counts[reason] = count
+	}
+	return counts
+}
+
+// accept hands the decoded message to the subscriber, and records that the round was reported by the quorum,
+// so the leaders of the later epochs of the round stand down
+func (v *MessageValidator) accept(msg *pubsub.Message, priceMsg *domain.PriceMessage) {
+	v.observations.MarkReported(priceMsg.Feed, priceMsg.Round)
+	msg.ValidatorData = priceMsg
+}
+
+func (v *MessageValidator) reject(reason string, from peer.ID, priceMsg *domain.PriceMessage, err error) pubsub.ValidationResult {
+	count := v.count(reason)
+	log.Infof("Rejected message %s from peer %s (%s, %d total): %v", priceMsg.MessageID, from, reason, count, err)
+	return pubsub.ValidationReject
+}
+
+func (v *MessageValidator) rejectObservation(reason string, from peer.ID, observation *domain.Observation, err error) pubsub.ValidationResult {
+	count := v.count(reason)
+	log.Infof("Rejected observation of %s for round %d from peer %s (%s, %d total): %v", observation.Observer, observation.Round, from, reason, count, err)
+	return pubsub.ValidationReject
+}
+
+func (v *MessageValidator) rejectCommitment(reason string, from peer.ID, commitment *domain.Commitment, err error) pubsub.ValidationResult {
+	count := v.count(reason)
+	log.Infof("Rejected commitment of %s for round %d from peer %s (%s, %d total): %v", commitment.Observer, commitment.Round, from, reason, count, err)
+	return pubsub.ValidationReject
+}
+
+func (v *MessageValidator) ignore(reason string, from peer.ID, priceMsg *domain.PriceMessage) pubsub.ValidationResult {
+	count := v.count(reason)
+	log.Debugf("Ignored message %s from peer %s (%s, %d total)", priceMsg.MessageID, from, reason, count)
+	return pubsub.ValidationIgnore
+}
+
+func (v *MessageValidator) count(reason string) uint64 {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.counts[reason]++
+	return v.counts[reason]
+}
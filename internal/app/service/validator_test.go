@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"chainlink-lite/internal/app/domain"
+
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	pb "github.com/libp2p/go-libp2p-pubsub/pb"
+)
+
+// newTestValidator returns the validator of the feed with a committee of a single signer, with a write quorum
+// of quorum signatures, and the signer
+func newTestValidator(t *testing.T, feed string, quorum string) (*MessageValidator, *SignerService) {
+	t.Helper()
+	member, key := testMember(t, "signer")
+	signer, err := NewSignerService(key, domain.SchemeEd25519)
+	if err != nil {
+		t.Fatalf("NewSignerService: %v", err)
+	}
+	committee := &domain.Committee{Version: 1, Members: []domain.Member{member}}
+	keys := NewKeyRegistry(committee, domain.RevocationList{}, time.Hour, time.Minute)
+	schedule, err := NewSchedule(keys, time.Minute, 0, 0, 10*time.Second)
+	if err != nil {
+		t.Fatalf("NewSchedule: %v", err)
+	}
+	q, err := domain.ParseQuorum(quorum)
+	if err != nil {
+		t.Fatalf("ParseQuorum: %v", err)
+	}
+	return NewMessageValidator(keys, feed, schedule, NewObservationPool(time.Hour), NewQuorumTracker(feed, keys, q), 2, 1, time.Minute), signer
+}
+
+// signedObservation returns an observation of the feed for the current round, signed by the signer
+func signedObservation(t *testing.T, signer *SignerService, feed string) domain.Observation {
+	t.Helper()
+	price, err := domain.ParsePrice("3456.78")
+	if err != nil {
+		t.Fatalf("ParsePrice: %v", err)
+	}
+	observation := domain.Observation{
+		Round:     time.Now().UnixNano() / int64(time.Minute),
+		Feed:      feed,
+		Price:     price,
+		Observer:  signer.ID(),
+		PublicKey: signer.PublicKey(),
+		CreatedAt: time.Now().Unix(),
+	}
+	observation.Signature, err = signer.SignObservation(context.Background(), &observation)
+	if err != nil {
+		t.Fatalf("SignObservation: %v", err)
+	}
+	return observation
+}
+
+// gossipMessage wraps the JSON encoding of v in a pubsub message
+func gossipMessage(t *testing.T, v any) *pubsub.Message {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	return &pubsub.Message{Message: &pb.Message{Data: data}}
+}
+
+func TestValidateObservationFeed(t *testing.T) {
+	tests := []struct {
+		name string
+		feed string
+		want pubsub.ValidationResult
+	}{
+		{name: "feed of the topic", feed: "ETH/USD", want: pubsub.ValidationAccept},
+		{name: "other feed", feed: "BTC/USD", want: pubsub.ValidationReject},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, signer := newTestValidator(t, "ETH/USD", "1")
+			observation := signedObservation(t, signer, tt.feed)
+			if got := v.ValidateObservation(context.Background(), "", gossipMessage(t, observation)); got != tt.want {
+				t.Fatalf("ValidateObservation = %v, want %v", got, tt.want)
+			}
+			if got := len(v.observations.Observations(tt.feed, observation.Round)); (got == 1) != (tt.want == pubsub.ValidationAccept) {
+				t.Errorf("pooled %d observations of %s", got, tt.feed)
+			}
+		})
+	}
+}
+
+// signedReport returns the report of the observation of the signer, signed by the signer alone
+func signedReport(t *testing.T, signer *SignerService, feed string) domain.PriceMessage {
+	t.Helper()
+	observation := signedObservation(t, signer, feed)
+	report := domain.PriceMessage{
+		MessageID:    "report",
+		Feed:         feed,
+		Round:        observation.Round,
+		Price:        observation.Price,
+		Observations: []domain.Observation{observation},
+		Publisher:    signer.ID(),
+		CreatedAt:    time.Now().Unix(),
+	}
+	signature, err := signer.SignReport(context.Background(), &report)
+	if err != nil {
+		t.Fatalf("SignReport: %v", err)
+	}
+	report.Signers = []string{signer.ID()}
+	report.PublicKeys = []string{signer.PublicKey()}
+	report.Signatures = []string{signature}
+	return report
+}
+
+func TestValidateReport(t *testing.T) {
+	tests := []struct {
+		name     string
+		feed     string
+		quorum   string
+		want     pubsub.ValidationResult
+		reason   string
+		reported bool
+	}{
+		{name: "signed by the quorum", feed: "ETH/USD", quorum: "1", want: pubsub.ValidationAccept, reported: true},
+		{name: "other feed", feed: "BTC/USD", quorum: "1", want: pubsub.ValidationReject, reason: RejectFeed},
+		{name: "below the quorum", feed: "ETH/USD", quorum: "2", want: pubsub.ValidationReject, reason: RejectQuorum},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, signer := newTestValidator(t, "ETH/USD", tt.quorum)
+			report := signedReport(t, signer, tt.feed)
+			if got := v.Validate(context.Background(), "", gossipMessage(t, report)); got != tt.want {
+				t.Fatalf("Validate = %v, want %v", got, tt.want)
+			}
+			if tt.reason != "" {
+				if got := v.Counts()[tt.reason]; got != 1 {
+					t.Errorf("%s count = %d, want 1", tt.reason, got)
+				}
+			}
+			if got := v.observations.Reported(tt.feed, report.Round); got != tt.reported {
+				t.Errorf("Reported = %t, want %t", got, tt.reported)
+			}
+		})
+	}
+}
+
+func TestValidateCommitmentOfOtherFeed(t *testing.T) {
+	v, signer := newTestValidator(t, "ETH/USD", "1")
+	commitment := domain.Commitment{
+		Round:     time.Now().UnixNano()/int64(time.Minute) + 1,
+		Feed:      "BTC/USD",
+		Digest:    domain.CommitmentDigest(signedObservation(t, signer, "BTC/USD")),
+		Observer:  signer.ID(),
+		PublicKey: signer.PublicKey(),
+		CreatedAt: time.Now().Unix(),
+	}
+	var err error
+	commitment.Signature, err = signer.SignCommitment(context.Background(), &commitment)
+	if err != nil {
+		t.Fatalf("SignCommitment: %v", err)
+	}
+	if got := v.ValidateCommitment(context.Background(), "", gossipMessage(t, commitment)); got != pubsub.ValidationReject {
+		t.Fatalf("ValidateCommitment = %v, want %v", got, pubsub.ValidationReject)
+	}
+	if got := v.Counts()[RejectFeed]; got != 1 {
+		t.Errorf("%s count = %d, want 1", RejectFeed, got)
+	}
+}
+
+func TestValidateObservationRound(t *testing.T) {
+	tests := []struct {
+		name   string
+		offset int64
+		want   pubsub.ValidationResult
+	}{
+		{name: "previous round", offset: -1, want: pubsub.ValidationIgnore},
+		{name: "current round", offset: 0, want: pubsub.ValidationAccept},
+		{name: "next round", offset: 1, want: pubsub.ValidationAccept},
+		{name: "round after the next", offset: 2, want: pubsub.ValidationIgnore},
+		{name: "far future round", offset: 1_000_000, want: pubsub.ValidationIgnore},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, signer := newTestValidator(t, "ETH/USD", "1")
+			observation := signedObservation(t, signer, "ETH/USD")
+			observation.Round += tt.offset
+			var err error
+			observation.Signature, err = signer.SignObservation(context.Background(), &observation)
+			if err != nil {
+				t.Fatalf("SignObservation: %v", err)
+			}
+			if got := v.ValidateObservation(context.Background(), "", gossipMessage(t, observation)); got != tt.want {
+				t.Fatalf("ValidateObservation = %v, want %v", got, tt.want)
+			}
+			if tt.want == pubsub.ValidationIgnore && v.Counts()[IgnoreRound] != 1 {
+				t.Errorf("%s count = %d, want 1", IgnoreRound, v.Counts()[IgnoreRound])
+			}
+		})
+	}
+}
+
+func TestValidateObservationBeforeReveal(t *testing.T) {
+	v, signer := newTestValidator(t, "ETH/USD", "1")
+	var err error
+	v.schedule, err = NewSchedule(v.keys, time.Minute, time.Nanosecond, 10*time.Second, 10*time.Second)
+	if err != nil {
+		t.Fatalf("NewSchedule: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		offset int64
+		want   pubsub.ValidationResult
+	}{
+		{name: "commit window closed", offset: 0, want: pubsub.ValidationAccept},
+		{name: "commit window open", offset: 1, want: pubsub.ValidationIgnore},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			observation := signedObservation(t, signer, "ETH/USD")
+			observation.Round += tt.offset
+			observation.Signature, err = signer.SignObservation(context.Background(), &observation)
+			if err != nil {
+				t.Fatalf("SignObservation: %v", err)
+			}
+			v.observations.AddCommitment(signer.ID(), domain.Commitment{
+				Round:  observation.Round,
+				Feed:   observation.Feed,
+				Digest: domain.CommitmentDigest(observation),
+			})
+			if got := v.ValidateObservation(context.Background(), "", gossipMessage(t, observation)); got != tt.want {
+				t.Fatalf("ValidateObservation = %v, want %v", got, tt.want)
+			}
+			if tt.want == pubsub.ValidationIgnore && v.Counts()[IgnoreEarlyReveal] != 1 {
+				t.Errorf("%s count = %d, want 1", IgnoreEarlyReveal, v.Counts()[IgnoreEarlyReveal])
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"bytes"
+	"chainlink-lite/internal/app/domain"
+	"crypto/sha256"
+)
+
+// WriterRank returns the rank of the node among the candidate writers of the report, 0 for the first writer
+// The candidates are the committee members whose key is not revoked, or the signers of the report and the node itself
+// without a committee. They are ranked by hashing the message ID with their node ID, so every node computes the same
+// ranking, whatever copy of the report it received, and the writer changes from report to report.
+func WriterRank(report *domain.PriceMessage, nodeID string, keys *KeyRegistry) int {
+	candidates := report.Signers
+	if committee := keys.Committee(); committee != nil {
+		candidates = make([]string, 0, committee.Size())
+		for _, m := range committee.Members {
+			if !keys.IsRevoked(m.PeerID) {
+				candidates = append(candidates, m.PeerID)
+			}
+		}
+	}
+
+	// A rotated key keeps the rank of the committee member it succeeded
+	owner := keys.Owner(nodeID)
+	own := writerScore(report.MessageID, owner)
+	rank := 0
+	for _, candidate := range candidates {
+		candidate = keys.Owner(candidate)
+		if candidate != owner && bytes.Compare(writerScore(report.MessageID, candidate), own) < 0 {
+			rank++
+		}
+	}
+	return rank
+}
+
+// writerScore returns the position of the node in the writer ranking of the message
+func writerScore(messageID string, nodeID string) []byte {
+	h := sha256.New()
+	h.Write([]byte(messageID))
+	h.Write([]byte{0})
+	h.Write([]byte(nodeID))
+	return h.Sum(nil)
+}
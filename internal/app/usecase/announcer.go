@@ -0,0 +1,46 @@
+package usecase
+
+import (
+	"chainlink-lite/internal/app/domain"
+	"chainlink-lite/internal/app/service"
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// RotationAnnouncer publishes the key rotations of the node on an interval,
+// so peers that missed an announcement or restarted since still accept the current key
+type RotationAnnouncer struct {
+	pubsub    *service.PubSubService
+	rotations []domain.KeyRotation
+	interval  time.Duration
+}
+
+func NewRotationAnnouncer(pubsub *service.PubSubService, rotations []domain.KeyRotation, interval time.Duration) *RotationAnnouncer {
+	return &RotationAnnouncer{
+		pubsub:    pubsub,
+		rotations: rotations,
+		interval:  interval,
+	}
+}
+
+func (a *RotationAnnouncer) Start(ctx context.Context) {
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, rotation := range a.rotations {
+				if err := a.pubsub.PublishRotation(rotation); err != nil {
+					log.Warnf("Failed to announce key rotation from %s to %s: %v", rotation.OldPeerID, rotation.NewPeerID, err)
+					continue
+				}
+				log.Debugf("Announced key rotation from %s to %s", rotation.OldPeerID, rotation.NewPeerID)
+			}
+		}
+	}
+}
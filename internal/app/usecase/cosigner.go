@@ -0,0 +1,127 @@
+package usecase
+
+import (
+	"chainlink-lite/internal/app/domain"
+	"chainlink-lite/internal/app/service"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	log "github.com/sirupsen/logrus"
+)
+
+// Cosigner answers the signing requests of the proposers of reports
+// A report is only signed if it passes the checks of the topic validator, its price agrees with the reference price
+// of the node, and the node did not sign another report for the same epoch of the round
+type Cosigner struct {
+	validator  *service.MessageValidator
+	repo       domain.PriceMessageRepository
+	references *service.ReferencePrices
+	tolerance  float64
+	signer     *service.ReportSigner
+
+	mu     sync.Mutex
+	signed map[proposalKey]string // message ID signed for each epoch of a round
+}
+
+type proposalKey struct {
+	feed  string
+	round int64
+	epoch int
+}
+
+// NewCosigner creates the cosigner
+// Reports whose price deviates from the reference price of the node by more than tolerance percent are not signed,
+// 0 disables the check
+func NewCosigner(validator *service.MessageValidator, repo domain.PriceMessageRepository, references *service.ReferencePrices,
+	tolerance float64, signer *service.ReportSigner) *Cosigner {
+	return &Cosigner{
+		validator:  validator,
+		repo:       repo,
+		references: references,
+		tolerance:  tolerance,
+		signer:     signer,
+		signed:     make(map[proposalKey]string),
+	}
+}
+
+// Sign checks the report proposed by the peer and returns the signature share of the node
+func (c *Cosigner) Sign(ctx context.Context, from peer.ID, report *domain.PriceMessage) (domain.SignatureShare, error) {
+	if err := c.validator.ValidateProposal(from, report); err != nil {
+		return domain.SignatureShare{}, err
+	}
+	if err := c.checkPrice(ctx, report); err != nil {
+		log.Warnf("Refused to sign round %d of %s proposed by %s: %v", report.Round, report.Feed, report.Publisher, err)
+		return domain.SignatureShare{}, err
+	}
+	if err := c.claim(report); err != nil {
+		log.Warnf("Refused to sign round %d of %s proposed by %s: %v", report.Round, report.Feed, report.Publisher, err)
+		return domain.SignatureShare{}, err
+	}
+
+	share, err := c.signer.Share(ctx, report)
+	if err != nil {
+		log.Warnf("Failed to sign message: %v", err)
+		return domain.SignatureShare{}, err
+	}
+	log.Infof("Signed round %d of %s at %s proposed by %s", report.Round, report.Feed, report.Price, report.Publisher)
+	return share, nil
+}
+
+// claim records that the node signs the report for its epoch
+// A leader that asks for the signatures of two different reports of its epoch is refused the second time
+func (c *Cosigner) claim(report *domain.PriceMessage) error {
+	key := proposalKey{feed: report.Feed, round: report.Round, epoch: report.Epoch}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if id, ok := c.signed[key]; ok && id != report.MessageID {
+		return fmt.Errorf("%w: %s for epoch %d of round %d", domain.ErrEquivocation, id, report.Epoch, report.Round)
+	}
+	for k := range c.signed {
+		if k.feed == key.feed && k.round < key.round {
+			delete(c.signed, k)
+		}
+	}
+	c.signed[key] = report.MessageID
+	return nil
+}
+
+// checkPrice checks the price of the report against the reference price of the node
+// A price that deviates by more than the tolerance is recorded as evidence against the publisher
+func (c *Cosigner) checkPrice(ctx context.Context, msg *domain.PriceMessage) error {
+	if c.tolerance <= 0 {
+		return nil
+	}
+	reference, err := c.references.Price(ctx, msg.Feed, msg.Round)
+	if err != nil {
+		return fmt.Errorf("no reference price: %v", err)
+	}
+	deviation, err := domain.Deviation(msg.Price, reference)
+	if err != nil {
+		return err
+	}
+	if deviation <= c.tolerance {
+		return nil
+	}
+
+	evidence := domain.Evidence{
+		Reporter:      c.signer.ID(),
+		Publisher:     msg.Publisher,
+		Feed:          msg.Feed,
+		Round:         msg.Round,
+		Epoch:         msg.Epoch,
+		MessageID:     msg.MessageID,
+		ProposedPrice: msg.Price,
+		ObservedPrice: reference,
+		Deviation:     deviation,
+		Report:        *msg,
+		CreatedAt:     time.Now().Unix(),
+	}
+	if err := c.repo.StoreEvidence(ctx, &evidence); err != nil {
+		log.Warnf("Failed to store evidence against %s: %v", msg.Publisher, err)
+	}
+	return fmt.Errorf("%w: %s deviates %.2f%% from %s", domain.ErrPriceDeviation, msg.Price, deviation, reference)
+}
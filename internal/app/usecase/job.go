@@ -0,0 +1,163 @@
+package usecase
+
+import (
+	"chainlink-lite/internal/app/domain"
+	"chainlink-lite/internal/app/service"
+	"context"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Job observes the price of a feed by running the task pipeline of a job spec every round
+// The sign and gossip tasks of the pipeline sign and publish the observation of the node, as a SourceObserver does
+type Job struct {
+	name     string
+	feed     string
+	pipeline *service.Pipeline
+	price    string // ID of the task whose output is the price the node observes
+	timeout  time.Duration
+	runs     domain.TaskRunRepository
+}
+
+var _ Observer = (*Job)(nil)
+var _ domain.PriceSource = (*Job)(nil)
+
+// NewJob creates the job of the spec, with the factories of the tasks computing its price by task type
+// The runs of its tasks are stored in runs
+func NewJob(spec domain.JobSpec, factories map[string]service.TaskFactory, schedule *service.Schedule,
+	pubsub *service.PubSubService, signer *service.ReportSigner, runs domain.TaskRunRepository) (*Job, error) {
+	if spec.Timeout <= 0 {
+		return nil, fmt.Errorf("job %s has no timeout", spec.Name)
+	}
+	observations := newObservationSigner(spec.Feed, *spec.Decimals, schedule, pubsub, signer)
+	tasks := make(map[string]service.TaskFactory, len(factories)+2)
+	for taskType, factory := range factories {
+		tasks[taskType] = factory
+	}
+	tasks[domain.TaskSign] = func(spec domain.TaskSpec) (service.Task, error) {
+		return newSignTask(spec, observations)
+	}
+	tasks[domain.TaskGossip] = func(spec domain.TaskSpec) (service.Task, error) {
+		return newGossipTask(spec, observations)
+	}
+
+	pipeline, err := service.NewPipeline(spec, tasks)
+	if err != nil {
+		return nil, err
+	}
+	sign, _ := pipeline.Task(pipeline.Output())
+	sign, _ = pipeline.Task(sign.Inputs[0])
+	return &Job{
+		name:     spec.Name,
+		feed:     spec.Feed,
+		pipeline: pipeline,
+		price:    sign.Inputs[0],
+		timeout:  spec.Timeout,
+		runs:     runs,
+	}, nil
+}
+
+// Observe runs the pipeline for the round, publishing the signed observation of the node
+func (j *Job) Observe(ctx context.Context, round int64) error {
+	log.Infof("Running job %s for round %d of %s", j.name, round, j.feed)
+	result, runs := j.run(ctx, round, j.pipeline.Output())
+	j.store(ctx, runs)
+	if result.Err != nil {
+		return fmt.Errorf("job %s: %w", j.name, result.Err)
+	}
+	return nil
+}
+
+// FetchPrice runs the tasks computing the price of the feed, without signing nor publishing it,
+// so the job is the reference price of the reports the node co-signs
+func (j *Job) FetchPrice(ctx context.Context, feed string) (domain.Price, error) {
+	if feed != j.feed {
+		return domain.Price{}, fmt.Errorf("%w: %s is not observed by job %s", domain.ErrUnsupportedFeed, feed, j.name)
+	}
+	result, runs := j.run(ctx, 0, j.price)
+	j.store(ctx, runs)
+	if result.Err != nil {
+		return domain.Price{}, fmt.Errorf("job %s: %w", j.name, result.Err)
+	}
+	price, ok := result.Value.(domain.Price)
+	if !ok {
+		return domain.Price{}, fmt.Errorf("%w: job %s: task %s returned no price", domain.ErrFailedToFetchPrice, j.name, j.price)
+	}
+	return price, nil
+}
+
+// run runs the pipeline for the round up to the task, within the timeout of the job
+func (j *Job) run(ctx context.Context, round int64, taskID string) (service.TaskResult, []domain.TaskRun) {
+	ctx, cancel := context.WithTimeout(ctx, j.timeout)
+	defer cancel()
+	return j.pipeline.Run(ctx, round, taskID)
+}
+
+// store records the task runs, a failure to record them does not fail the job
+func (j *Job) store(ctx context.Context, runs []domain.TaskRun) {
+	if err := j.runs.StoreTaskRuns(ctx, runs); err != nil {
+		log.Warnf("Failed to store the task runs of job %s: %v", j.name, err)
+	}
+}
+
+// signTask signs the observation of the node at the price of its input
+type signTask struct {
+	observations *observationSigner
+}
+
+func newSignTask(spec domain.TaskSpec, observations *observationSigner) (*signTask, error) {
+	if err := service.DecodeTaskParams(spec, &struct{}{}); err != nil {
+		return nil, err
+	}
+	if len(spec.Inputs) != 1 {
+		return nil, fmt.Errorf("sign takes a single input")
+	}
+	return &signTask{observations: observations}, nil
+}
+
+// Run returns the signed observation of the node for the round, with its commitment if the round has a commit window
+func (t *signTask) Run(ctx context.Context, vars service.TaskVars, inputs []service.TaskResult) (interface{}, error) {
+	values, err := service.TaskInputs(inputs, 1)
+	if err != nil {
+		return nil, err
+	}
+	price, ok := values[0].(domain.Price)
+	if !ok {
+		return nil, fmt.Errorf("%w: input is not a price", domain.ErrInvalidPrice)
+	}
+	return t.observations.sign(ctx, vars.Round, price)
+}
+
+// gossipTask publishes the signed observation of its input on the topic of the feed
+type gossipTask struct {
+	observations *observationSigner
+}
+
+func newGossipTask(spec domain.TaskSpec, observations *observationSigner) (*gossipTask, error) {
+	if err := service.DecodeTaskParams(spec, &struct{}{}); err != nil {
+		return nil, err
+	}
+	if len(spec.Inputs) != 1 {
+		return nil, fmt.Errorf("gossip takes a single input")
+	}
+	return &gossipTask{observations: observations}, nil
+}
+
+// Run publishes the observation, after its commitment if it has one
+func (t *gossipTask) Run(ctx context.Context, _ service.TaskVars, inputs []service.TaskResult) (interface{}, error) {
+	values, err := service.TaskInputs(inputs, 1)
+	if err != nil {
+		return nil, err
+	}
+	signed, ok := values[0].(*signedObservation)
+	if !ok {
+		return nil, fmt.Errorf("input is not a signed observation")
+	}
+	if err := t.observations.publish(ctx, signed); err != nil {
+		return nil, err
+	}
+	log.Infof("%s price observed: %s", signed.Observation.Feed, signed.Observation.Price)
+	return nil, nil
+}
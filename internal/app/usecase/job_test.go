@@ -0,0 +1,68 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"chainlink-lite/internal/app/domain"
+	"chainlink-lite/internal/app/service"
+)
+
+// taskFunc is a task running a function
+type taskFunc func(ctx context.Context, vars service.TaskVars, inputs []service.TaskResult) (interface{}, error)
+
+func (f taskFunc) Run(ctx context.Context, vars service.TaskVars, inputs []service.TaskResult) (interface{}, error) {
+	return f(ctx, vars, inputs)
+}
+
+// discardRuns drops the task runs
+type discardRuns struct{}
+
+func (discardRuns) StoreTaskRuns(context.Context, []domain.TaskRun) error {
+	return nil
+}
+
+func TestJobRunsWithinTimeout(t *testing.T) {
+	decimals := uint8(8)
+	spec := domain.JobSpec{
+		Name:        "link-usd",
+		Feed:        "LINK/USD",
+		Topic:       "oracle/link-usd",
+		Schedule:    30 * time.Second,
+		WriteQuorum: "3",
+		Decimals:    &decimals,
+		Timeout:     50 * time.Millisecond,
+		Tasks: []domain.TaskSpec{
+			{ID: "api", Type: domain.TaskHTTP},
+			{ID: "sign", Type: domain.TaskSign, Inputs: []string{"api"}},
+			{ID: "gossip", Type: domain.TaskGossip, Inputs: []string{"sign"}},
+		},
+	}
+	// The API never answers
+	hang := taskFunc(func(ctx context.Context, _ service.TaskVars, _ []service.TaskResult) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	factories := map[string]service.TaskFactory{
+		domain.TaskHTTP: func(domain.TaskSpec) (service.Task, error) { return hang, nil },
+	}
+	job, err := NewJob(spec, factories, nil, nil, nil, discardRuns{})
+	if err != nil {
+		t.Fatalf("NewJob: %v", err)
+	}
+
+	start := time.Now()
+	if _, err := job.FetchPrice(context.Background(), spec.Feed); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("FetchPrice = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("FetchPrice returned after %s, want the timeout of the job", elapsed)
+	}
+
+	spec.Timeout = 0
+	if _, err := NewJob(spec, factories, nil, nil, nil, discardRuns{}); err == nil {
+		t.Errorf("NewJob without a timeout succeeded")
+	}
+}
@@ -0,0 +1,132 @@
+package usecase
+
+import (
+	"chainlink-lite/internal/app/domain"
+	"chainlink-lite/internal/app/service"
+	"chainlink-lite/internal/util"
+	"context"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Observer publishes the signed observation of the node for a round of a feed
+type Observer interface {
+	Observe(ctx context.Context, round int64) error
+}
+
+// SourceObserver observes the price of a feed fetched from a price source
+type SourceObserver struct {
+	source domain.PriceSource
+	feed   string
+	signer *observationSigner
+}
+
+var _ Observer = (*SourceObserver)(nil)
+
+func NewSourceObserver(source domain.PriceSource, feed string, decimals uint8, schedule *service.Schedule,
+	pubsub *service.PubSubService, signer *service.ReportSigner) *SourceObserver {
+	return &SourceObserver{
+		source: source,
+		feed:   feed,
+		signer: newObservationSigner(feed, decimals, schedule, pubsub, signer),
+	}
+}
+
+// Observe fetches the price and publishes the signed observation of the node for the round
+func (o *SourceObserver) Observe(ctx context.Context, round int64) error {
+	log.Infof("Fetching %s price", o.feed)
+	price, err := o.source.FetchPrice(ctx, o.feed)
+	if err != nil {
+		return err
+	}
+	signed, err := o.signer.sign(ctx, round, price)
+	if err != nil {
+		return err
+	}
+	log.Infof("%s price fetched: %s", o.feed, signed.Observation.Price)
+	return o.signer.publish(ctx, signed)
+}
+
+// signedObservation is the observation of the node for a round, with its commitment if the round has a commit window
+type signedObservation struct {
+	Observation domain.Observation `json:"observation"`
+	Commitment  *domain.Commitment `json:"commitment,omitempty"`
+}
+
+// observationSigner signs and publishes the observations of the node for a feed
+type observationSigner struct {
+	feed     string
+	decimals uint8
+	schedule *service.Schedule
+	pubsub   *service.PubSubService
+	signer   *service.ReportSigner
+}
+
+func newObservationSigner(feed string, decimals uint8, schedule *service.Schedule, pubsub *service.PubSubService,
+	signer *service.ReportSigner) *observationSigner {
+	return &observationSigner{
+		feed:     feed,
+		decimals: decimals,
+		schedule: schedule,
+		pubsub:   pubsub,
+		signer:   signer,
+	}
+}
+
+// sign returns the signed observation of the price for the round
+// With a commit window, the observation is salted and comes with the signed commitment to it
+func (s *observationSigner) sign(ctx context.Context, round int64, fetched domain.Price) (*signedObservation, error) {
+	// Observations carry the decimals of the feed, so their prices have a single encoding
+	price := fetched.Round(s.decimals)
+	if err := price.Check(s.decimals); err != nil {
+		return nil, fmt.Errorf("price %s of %s: %w", fetched, s.feed, err)
+	}
+
+	observation := domain.Observation{
+		Round:     round,
+		Feed:      s.feed,
+		Price:     price,
+		CreatedAt: time.Now().Unix(),
+	}
+	if !s.schedule.CommitReveal() {
+		if err := s.signer.SignObservation(ctx, &observation); err != nil {
+			return nil, err
+		}
+		return &signedObservation{Observation: observation}, nil
+	}
+
+	var err error
+	if observation.Salt, err = util.GenerateSalt(); err != nil {
+		return nil, err
+	}
+	if err := s.signer.SignObservation(ctx, &observation); err != nil {
+		return nil, err
+	}
+	commitment := domain.Commitment{
+		Round:     round,
+		Feed:      s.feed,
+		Digest:    domain.CommitmentDigest(observation),
+		CreatedAt: time.Now().Unix(),
+	}
+	if err := s.signer.SignCommitment(ctx, &commitment); err != nil {
+		return nil, err
+	}
+	return &signedObservation{Observation: observation, Commitment: &commitment}, nil
+}
+
+// publish publishes the signed observation
+// With a commitment, the commitment is published first, and the observation is revealed once the commit window closed
+func (s *observationSigner) publish(ctx context.Context, signed *signedObservation) error {
+	if signed.Commitment == nil {
+		return s.pubsub.PublishObservation(signed.Observation)
+	}
+	if err := s.pubsub.PublishCommitment(*signed.Commitment); err != nil {
+		return err
+	}
+	if !sleepUntil(ctx, s.schedule.RevealStart(signed.Observation.Round)) {
+		return ctx.Err()
+	}
+	return s.pubsub.PublishObservation(signed.Observation)
+}
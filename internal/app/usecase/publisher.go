@@ -5,69 +5,143 @@ import (
 	"chainlink-lite/internal/app/service"
 	"chainlink-lite/internal/util"
 	"context"
+	"fmt"
 	"time"
 
 	log "github.com/sirupsen/logrus"
 )
 
+// Publisher runs the aggregation rounds of a feed
+// Every round, the observer publishes the signed observation of the node, after a commitment to it if the round has a commit window.
+// The leader of the epoch then builds a report from the median of the observations of the round it collected,
+// asks the peers to co-sign it over the signing protocol, and announces it on the topic once it is signed by the quorum.
+// If no report was seen when an epoch starts, its leader takes over.
 type Publisher struct {
-	ethClient domain.EthPriceTicker
-	interval  time.Duration
-	pubsub    *service.PubSubService
-	signer    *service.SignerService
+	observer        Observer
+	feed            string
+	schedule        *service.Schedule
+	minObservations int
+	pubsub          *service.PubSubService
+	observations    *service.ObservationPool
+	signing         *service.SignProtocol
+	quorum          *service.QuorumTracker
+	pending         *service.PendingReports
+	signer          *service.ReportSigner
 }
 
-func NewPublisher(ethClient domain.EthPriceTicker, interval time.Duration, pubsub *service.PubSubService, signer *service.SignerService) *Publisher {
+func NewPublisher(observer Observer, feed string, schedule *service.Schedule, minObservations int,
+	pubsub *service.PubSubService, observations *service.ObservationPool, signing *service.SignProtocol,
+	quorum *service.QuorumTracker, pending *service.PendingReports, signer *service.ReportSigner) *Publisher {
 	return &Publisher{
-		ethClient: ethClient,
-		interval:  interval,
-		pubsub:    pubsub,
-		signer:    signer,
+		observer:        observer,
+		feed:            feed,
+		schedule:        schedule,
+		minObservations: minObservations,
+		pubsub:          pubsub,
+		observations:    observations,
+		signing:         signing,
+		quorum:          quorum,
+		pending:         pending,
+		signer:          signer,
 	}
 }
 
 func (p *Publisher) Start(ctx context.Context) {
-	ticker := time.NewTicker(p.interval)
-	defer ticker.Stop()
-
 	for {
-		select {
-		case <-ctx.Done():
+		// Every node observes the same round at the same time
+		round := p.schedule.Round(time.Now()) + 1
+		if !sleepUntil(ctx, p.schedule.RoundStart(round)) {
 			return
-		case <-ticker.C:
-			log.Info("Fetching ETH price")
-			price, err := p.ethClient.FetchPrice()
-			if err != nil {
-				log.Warnf("Failed to fetch ETH price: %v", err)
-				continue
-			}
-			log.Info("ETH price fetched: ", price)
+		}
 
-			signature, err := p.signer.SignMessage(price)
-			if err != nil {
-				log.Warnf("Failed to sign message: %v", err)
-				continue
-			}
+		// The node may still lead the round with the observations of its peers
+		if err := p.observer.Observe(ctx, round); err != nil {
+			log.Warnf("Failed to observe round %d of %s: %v", round, p.feed, err)
+		}
 
-			id, err := util.GenerateUUID()
-			if err != nil {
-				log.Warnf("Failed to generate UUID: %v", err)
-				continue
+		for epoch := 0; epoch < p.schedule.Epochs(); epoch++ {
+			if !sleepUntil(ctx, p.schedule.EpochStart(round, epoch)) {
+				return
 			}
-			priceMsg := domain.PriceMessage{
-				MessageID:  id,
-				Price:      price,
-				Publisher:  p.pubsub.GetNodeID(),
-				Signers:    []string{p.pubsub.GetNodeID()},
-				Signatures: []string{signature},
-				CreatedAt:  time.Now().Unix(),
+			if p.observations.Reported(p.feed, round) {
+				break
 			}
 
-			if err := p.pubsub.Publish(&priceMsg); err != nil {
-				log.Warnf("Failed to publish price message: %v", err)
+			if !p.schedule.Leads(p.feed, round, epoch, p.signer.ID()) {
+				if epoch > 0 {
+					leader := p.schedule.Leader(p.feed, round, epoch)
+					log.Infof("No report for round %d of %s yet, %s leads epoch %d", round, p.feed, leader, epoch)
+				}
 				continue
 			}
-			log.Info("Price message published: ", priceMsg)
+			if err := p.report(ctx, round, epoch); err != nil {
+				log.Warnf("Failed to report round %d of %s: %v", round, p.feed, err)
+			}
+			break
 		}
+		p.observations.Prune(p.feed, round)
+	}
+}
+
+// report builds the report of the round from the median of the collected observations, signs it,
+// collects the signatures of the peers and announces it once it is signed by the quorum
+func (p *Publisher) report(ctx context.Context, round int64, epoch int) error {
+	observations := p.observations.Observations(p.feed, round)
+	if len(observations) < p.minObservations {
+		return fmt.Errorf("%w: %d observations, %d required", domain.ErrInsufficientObservations, len(observations), p.minObservations)
+	}
+	median, err := domain.Median(observations)
+	if err != nil {
+		return err
+	}
+
+	id, err := util.GenerateUUID()
+	if err != nil {
+		return err
+	}
+	priceMsg := domain.PriceMessage{
+		MessageID:    id,
+		Feed:         p.feed,
+		Round:        round,
+		Epoch:        epoch,
+		Price:        median,
+		Observations: observations,
+		Publisher:    p.signer.ID(),
+		CreatedAt:    time.Now().Unix(),
+	}
+
+	// Sign the canonical encoding of the whole report
+	if err := p.signer.AddSignature(ctx, &priceMsg); err != nil {
+		return err
+	}
+
+	collectCtx, cancel := context.WithDeadline(ctx, p.schedule.CollectionDeadline(round, epoch))
+	defer cancel()
+	threshold := p.quorum.Threshold()
+	report, err := p.signing.Collect(collectCtx, &priceMsg, p.pubsub.Peers(), threshold, p.pending)
+	if err != nil {
+		return err
+	}
+	if report.SignatureCount() < threshold {
+		return fmt.Errorf("%w: %d signatures, %d required", domain.ErrInsufficientSignatures, report.SignatureCount(), threshold)
+	}
+
+	if err := p.pubsub.Publish(report); err != nil {
+		return err
+	}
+	log.Info("Price message published: ", report)
+	return nil
+}
+
+// sleepUntil waits until t, returns false if the context is done first
+func sleepUntil(ctx context.Context, t time.Time) bool {
+	timer := time.NewTimer(time.Until(t))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
 	}
 }
@@ -4,6 +4,8 @@ import (
 	"chainlink-lite/internal/app/domain"
 	"chainlink-lite/internal/app/service"
 	"context"
+	"errors"
+	"sync"
 	"time"
 
 	log "github.com/sirupsen/logrus"
@@ -12,19 +14,39 @@ import (
 type Subscriber struct {
 	pubsub        *service.PubSubService
 	repo          domain.PriceMessageRepository
-	minSignatures int
-	minInterval   time.Duration
-	signer        *service.SignerService
+	keys          *service.KeyRegistry
+	quorum        *service.QuorumTracker
+	policy        domain.WritePolicy
+	writerTimeout time.Duration
+	pending       *service.PendingReports
+	signer        *service.ReportSigner
+
+	mu      sync.Mutex
+	writing map[writeKey]struct{} // rounds scheduled to be written, until a later round of the feed is
+}
+
+type writeKey struct {
+	feed  string
+	round int64
 }
 
-func NewSubscriber(pubsub *service.PubSubService, repo domain.PriceMessageRepository, minSignatures int,
-	minInterval time.Duration, signer *service.SignerService) *Subscriber {
+// NewSubscriber creates the subscriber, writing the announced reports signed by the quorum of the committee of the key registry
+// with the write policy of their feed. The signatures of every copy of a report are merged in the pending reports.
+// A finalized report is written by the first node of its writer ranking, the next nodes take over one after the other
+// every writerTimeout if the report was not written yet
+func NewSubscriber(pubsub *service.PubSubService, repo domain.PriceMessageRepository, keys *service.KeyRegistry, quorum *service.QuorumTracker,
+	policy domain.WritePolicy, writerTimeout time.Duration,
+	pending *service.PendingReports, signer *service.ReportSigner) *Subscriber {
 	return &Subscriber{
 		pubsub:        pubsub,
 		repo:          repo,
-		minSignatures: minSignatures,
-		minInterval:   minInterval,
+		keys:          keys,
+		quorum:        quorum,
+		policy:        policy,
+		writerTimeout: writerTimeout,
+		pending:       pending,
 		signer:        signer,
+		writing:       make(map[writeKey]struct{}),
 	}
 }
 
@@ -38,61 +60,101 @@ func (s *Subscriber) Start(ctx context.Context) {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			msg, err := s.pubsub.Receive(true)
+			// The leader handles its own announcement too, it may be the first writer
+			msg, err := s.pubsub.Receive(false)
 			if err != nil {
 				log.Warnf("Failed to receive message: %v", err)
 				continue
 			}
-			if msg == nil {
-				// Message from self, skip.
-				continue
-			}
 
 			log.Info("Received message: ", msg)
 
-			if len(msg.Signatures) >= s.minSignatures {
-				msg.Writer = s.pubsub.GetNodeID()
-				// Store the message if at least s.minInterval has passed since the last write
-				_, err := s.repo.StorePriceIfAllowed(ctx, msg, s.minInterval)
-				if err != nil {
-					log.Warnf("Failed to store message: %v", err)
-					continue
-				}
-			} else {
-				// Check if the message has already been signed by the current node
-				// If not, sign the message and republish it
-				if !s.AlreadySigned(*msg) {
-					signedMsg, err := s.signer.SignMessage(msg.Price)
-					if err != nil {
-						log.Warnf("Failed to sign message: %v", err)
-						continue
-					}
-					msg.Signatures = append(msg.Signatures, signedMsg)
-					msg.Signers = append(msg.Signers, s.pubsub.GetNodeID())
-
-					// Republish the message
-					if err := s.pubsub.Publish(msg); err != nil {
-						log.Printf("Failed to re-publish message: %v", err)
-					}
-				}
-			}
+			s.handle(ctx, msg)
 		}
 	}
 }
 
-// Check if the message has already been signed by the current node
-func (s *Subscriber) AlreadySigned(priceMsg domain.PriceMessage) bool {
-	for _, sig := range priceMsg.Signatures {
-		signedMsg, err := s.signer.SignMessage(priceMsg.Price)
-		if err != nil {
-			log.Warnf("Failed to sign message: %v", err)
-			continue
+// handle merges the announced report into the pending report, and schedules the write once the quorum is met
+// Later copies of a finalized report are skipped
+// Co-signers answer the signing requests of the proposer, so only finalized reports are expected on the topic
+func (s *Subscriber) handle(ctx context.Context, msg *domain.PriceMessage) {
+	merged, _, err := s.pending.Merge(msg)
+	if errors.Is(err, domain.ErrReportFinalized) || errors.Is(err, domain.ErrReportExpired) {
+		log.Debugf("Skipping message %s: %v", msg.MessageID, err)
+		return
+	}
+	if err != nil {
+		log.Warnf("Failed to merge message %s: %v", msg.MessageID, err)
+		return
+	}
+
+	threshold := s.quorum.Threshold()
+	if merged.SignatureCount() < threshold {
+		log.Debugf("Message %s has %d of %d signatures", merged.MessageID, merged.SignatureCount(), threshold)
+		return
+	}
+
+	// The writer signs as the signers of the report do, so the writer ranking can be checked from the row
+	merged.Writer = s.signer.ID()
+	merged.Quorum = threshold
+	// List the signers of an aggregate signature, so the row can be verified and the writers ranked
+	if err := s.signer.ResolveSigners(merged); err != nil {
+		log.Warnf("Failed to resolve signers: %v", err)
+		return
+	}
+	s.pending.Finalize(merged.MessageID)
+	s.pending.Prune(merged.Feed, merged.Round)
+	s.scheduleWrite(ctx, merged)
+}
+
+// scheduleWrite writes the report once the nodes ranked before this node had the time to write it
+// Later copies of a report are dropped once its round is scheduled
+func (s *Subscriber) scheduleWrite(ctx context.Context, msg *domain.PriceMessage) {
+	key := writeKey{feed: msg.Feed, round: msg.Round}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.writing[key]; ok {
+		return
+	}
+	for k := range s.writing {
+		if k.feed == key.feed && k.round < key.round {
+			delete(s.writing, k)
 		}
+	}
+	s.writing[key] = struct{}{}
+
+	rank := service.WriterRank(msg, msg.Writer, s.keys)
+	log.Debugf("Ranked %d to write round %d of %s", rank, msg.Round, msg.Feed)
+	go func() {
+		if !sleepUntil(ctx, time.Now().Add(time.Duration(rank)*s.writerTimeout)) {
+			return
+		}
+		s.write(ctx, msg, rank)
+	}()
+}
 
-		if sig == signedMsg {
-			log.Debug("Skipping message already signed by self")
-			return true
+// write stores the report if a write trigger of its feed fires
+// Nodes that are not the first writer only take over if no writer evaluated the triggers of the round yet,
+// so a round skipped by the write policy is not evaluated again by every node of the ranking
+func (s *Subscriber) write(ctx context.Context, msg *domain.PriceMessage, rank int) {
+	if rank > 0 {
+		evaluated, err := s.repo.IsEvaluated(ctx, msg.Feed, msg.Round)
+		if err != nil {
+			log.Warnf("Failed to check round %d of %s: %v", msg.Round, msg.Feed, err)
+			return
+		}
+		if evaluated {
+			return
 		}
+		log.Infof("Round %d of %s was not evaluated yet, taking over as writer %d", msg.Round, msg.Feed, rank)
+	}
+
+	trigger, err := s.repo.StorePriceIfTriggered(ctx, msg, s.policy)
+	if err != nil {
+		log.Warnf("Failed to store message: %v", err)
+		return
+	}
+	if trigger != domain.TriggerNone {
+		log.Infof("Stored round %d of %s at %s (%s trigger)", msg.Round, msg.Feed, msg.Price, trigger)
 	}
-	return false
 }
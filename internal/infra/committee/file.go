@@ -0,0 +1,76 @@
+package committee
+
+// Loads the committee definition from a YAML file
+
+import (
+	"fmt"
+	"os"
+
+	"chainlink-lite/internal/app/domain"
+	"chainlink-lite/internal/app/service"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"gopkg.in/yaml.v3"
+)
+
+// Load reads and validates the committee file at path
+func Load(path string) (*domain.Committee, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read committee file: %v", err)
+	}
+
+	var committee domain.Committee
+	if err := yaml.Unmarshal(data, &committee); err != nil {
+		return nil, fmt.Errorf("failed to parse committee file: %v", err)
+	}
+
+	if err := validate(&committee); err != nil {
+		return nil, fmt.Errorf("invalid committee file %s: %v", path, err)
+	}
+	return &committee, nil
+}
+
+// validate checks the version, that every member's public key derives its node ID,
+// and that every member proves it holds the private key of its BLS public key
+func validate(committee *domain.Committee) error {
+	if committee.Version <= 0 {
+		return fmt.Errorf("version must be a positive number")
+	}
+	if committee.Size() == 0 {
+		return fmt.Errorf("committee has no members")
+	}
+
+	seen := make(map[string]struct{}, committee.Size())
+	hosts := make(map[string]struct{}, committee.Size())
+	for _, m := range committee.Members {
+		if _, ok := seen[m.PeerID]; ok {
+			return fmt.Errorf("member %s is listed more than once", m.PeerID)
+		}
+		seen[m.PeerID] = struct{}{}
+
+		// Signing requests are sent to the host of a member, so two members can not share one
+		if m.HostPeerID != "" {
+			if _, err := peer.Decode(m.HostPeerID); err != nil {
+				return fmt.Errorf("member %q: invalid host peer ID: %v", m.Name, err)
+			}
+		}
+		if _, ok := hosts[m.Host()]; ok {
+			return fmt.Errorf("host %s of member %q is listed more than once", m.Host(), m.Name)
+		}
+		hosts[m.Host()] = struct{}{}
+
+		if _, err := service.VerifyIdentity(m.PeerID, m.PublicKey); err != nil {
+			return fmt.Errorf("member %q: %v", m.Name, err)
+		}
+		if m.BLSPublicKey != "" {
+			if err := service.ValidateBLSPublicKey(m.BLSPublicKey); err != nil {
+				return fmt.Errorf("member %q: invalid BLS public key: %v", m.Name, err)
+			}
+			if err := service.VerifyProofOfPossession(m.PeerID, m.BLSPublicKey, m.BLSProofOfPossession); err != nil {
+				return fmt.Errorf("member %q: %v", m.Name, err)
+			}
+		}
+	}
+	return nil
+}
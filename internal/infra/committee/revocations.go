@@ -0,0 +1,33 @@
+package committee
+
+// Loads the list of revoked node keys from a YAML file
+
+import (
+	"fmt"
+	"os"
+
+	"chainlink-lite/internal/app/domain"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"gopkg.in/yaml.v3"
+)
+
+// LoadRevocations reads and validates the revocation list at path
+func LoadRevocations(path string) (domain.RevocationList, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return domain.RevocationList{}, fmt.Errorf("failed to read revocation list: %v", err)
+	}
+
+	var revocations domain.RevocationList
+	if err := yaml.Unmarshal(data, &revocations); err != nil {
+		return domain.RevocationList{}, fmt.Errorf("failed to parse revocation list: %v", err)
+	}
+
+	for _, r := range revocations.Revoked {
+		if _, err := peer.Decode(r.PeerID); err != nil {
+			return domain.RevocationList{}, fmt.Errorf("invalid revocation list %s: invalid node ID %q: %v", path, r.PeerID, err)
+		}
+	}
+	return revocations, nil
+}
@@ -5,6 +5,7 @@ package db
 import (
 	"context"
 	"fmt"
+	"sync"
 
 	"time"
 
@@ -16,12 +17,24 @@ import (
 )
 
 type PgPriceMessageRepository struct {
+	// A connection is not safe for concurrent use
+	mu sync.Mutex
 	db *pgx.Conn
 }
 
 var _ domain.PriceMessageRepository = (*PgPriceMessageRepository)(nil)
 
 func NewPriceMessageRepository(ctx context.Context, connString string) (*PgPriceMessageRepository, error) {
+	db, err := connect(ctx, connString)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PgPriceMessageRepository{db: db}, nil
+}
+
+// connect opens a connection to the database
+func connect(ctx context.Context, connString string) (*pgx.Conn, error) {
 	config, err := pgx.ParseConfig(connString)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse database config: %v", err)
@@ -31,62 +44,131 @@ func NewPriceMessageRepository(ctx context.Context, connString string) (*PgPrice
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %v", err)
 	}
-
-	return &PgPriceMessageRepository{db: db}, nil
+	return db, nil
 }
 
-// Store the priceMsg if at least minInterval has passed since the last write
+// Store the priceMsg if a trigger of the policy fires, against the last answer written for the feed
 // Timestamp is used to check the last write time
-// Returns true if the message was stored, false if it was skipped
-func (conn *PgPriceMessageRepository) StorePriceIfAllowed(ctx context.Context, priceMsg *domain.PriceMessage, minInterval time.Duration) (bool, error) {
+// The round is recorded as evaluated whether the message is stored or skipped
+// Returns the trigger that fired, or TriggerNone if the message was skipped
+func (conn *PgPriceMessageRepository) StorePriceIfTriggered(ctx context.Context, priceMsg *domain.PriceMessage, policy domain.WritePolicy) (domain.WriteTrigger, error) {
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+
 	tx, err := conn.db.Begin(ctx)
 	if err != nil {
 		log.Debugf("Failed to start transaction: %v", err)
-		return false, err
+		return domain.TriggerNone, err
 	}
 	defer tx.Rollback(ctx) //nolint:all
 
-	lockID := int64(1)
-	_, err = tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1)", lockID)
+	// Writes of different feeds do not depend on each other, lock the feed only
+	_, err = tx.Exec(ctx, "SELECT pg_advisory_xact_lock(hashtext($1))", priceMsg.Feed)
 	if err != nil {
 		log.Warnf("Failed to acquire advisory lock: %v", err)
-		return false, err
+		return domain.TriggerNone, err
 	}
 
+	var lastAnswer string
 	var lastTimestamp time.Time
-	err = tx.QueryRow(ctx, "SELECT timestamp FROM eth_price_messages ORDER BY timestamp DESC LIMIT 1").Scan(&lastTimestamp)
-	if err != nil {
-		if err == pgx.ErrNoRows {
-			// No rows found, use Unix epoch as the default value
-			lastTimestamp = time.Unix(0, 0)
-		} else {
-			log.Debugf("Failed to get latest timestamp: %v", err)
-			return false, err
-		}
+	err = tx.QueryRow(ctx, "SELECT price::text, timestamp FROM price_messages WHERE feed = $1 ORDER BY timestamp DESC LIMIT 1", priceMsg.Feed).Scan(&lastAnswer, &lastTimestamp)
+	// No rows found leaves no last answer, so the initial trigger fires
+	if err != nil && err != pgx.ErrNoRows {
+		log.Debugf("Failed to get latest answer: %v", err)
+		return domain.TriggerNone, err
 	}
-
-	if time.Since(lastTimestamp) >= minInterval {
-		query := "INSERT INTO eth_price_messages (message_id, price, publisher, writer, signers, signatures, created_at, timestamp) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) ON CONFLICT DO NOTHING"
-		_, err = tx.Exec(ctx, query, priceMsg.MessageID, priceMsg.Price, priceMsg.Publisher, priceMsg.Writer, priceMsg.Signers, priceMsg.Signatures, time.Unix(priceMsg.CreatedAt, 0), time.Now())
+	var lastPrice domain.Price
+	if lastAnswer != "" {
+		// NUMERIC keeps the decimals of the stored price, so its text is the canonical string
+		lastPrice, err = domain.ParsePrice(lastAnswer)
 		if err != nil {
-			log.Debugf("Failed to store ETH price in the database: %v", err)
-			return false, err
+			log.Debugf("Failed to parse latest answer: %v", err)
+			return domain.TriggerNone, err
 		}
-	} else {
-		log.Debug("Not enough time has passed since the last message")
-		return false, nil
+	}
+
+	// Later writers of the ranking skip the round once it is evaluated, so they do not take over a skipped round
+	_, err = tx.Exec(ctx, "INSERT INTO evaluated_rounds (feed, round) VALUES ($1, $2) ON CONFLICT (feed) DO UPDATE SET round = GREATEST(evaluated_rounds.round, EXCLUDED.round)",
+		priceMsg.Feed, priceMsg.Round)
+	if err != nil {
+		log.Debugf("Failed to record evaluated round: %v", err)
+		return domain.TriggerNone, err
+	}
+
+	trigger := policy.Trigger(priceMsg.Price, lastPrice, lastTimestamp, time.Now())
+	if trigger == domain.TriggerNone {
+		log.Debugf("No write trigger fired for %s at %s, last answer %s", priceMsg.Feed, priceMsg.Price, lastPrice)
+		return domain.TriggerNone, tx.Commit(ctx)
+	}
+
+	signatures := priceMsg.Signatures
+	if signatures == nil {
+		signatures = []string{}
+	}
+	// Aggregate columns are left NULL for individually signed messages
+	var aggregateSignature, signerBitmap *string
+	var committeeVersion *int
+	if priceMsg.Aggregate != nil {
+		aggregateSignature = &priceMsg.Aggregate.Signature
+		signerBitmap = &priceMsg.Aggregate.Bitmap
+		committeeVersion = &priceMsg.Aggregate.CommitteeVersion
+	}
+
+	query := "INSERT INTO price_messages (message_id, feed, round, epoch, price, trigger, publisher, writer, signers, public_keys, signatures, quorum, observations, payload_version, aggregate_signature, signer_bitmap, committee_version, created_at, timestamp) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19) ON CONFLICT DO NOTHING"
+	tag, err := tx.Exec(ctx, query, priceMsg.MessageID, priceMsg.Feed, priceMsg.Round, priceMsg.Epoch, priceMsg.Price, string(trigger), priceMsg.Publisher, priceMsg.Writer, priceMsg.Signers, priceMsg.PublicKeys,
+		signatures, priceMsg.Quorum, priceMsg.Observations, int16(domain.SigningPayloadVersion), aggregateSignature, signerBitmap, committeeVersion, time.Unix(priceMsg.CreatedAt, 0), time.Now())
+	if err != nil {
+		log.Debugf("Failed to store price in the database: %v", err)
+		return domain.TriggerNone, err
+	}
+	if tag.RowsAffected() == 0 {
+		log.Debugf("Round %d of %s was already written", priceMsg.Round, priceMsg.Feed)
+		return domain.TriggerNone, tx.Commit(ctx)
 	}
 
 	err = tx.Commit(ctx)
 	if err != nil {
 		log.Debugf("Failed to commit transaction: %v", err)
+		return domain.TriggerNone, err
+	}
+
+	return trigger, nil
+}
+
+// Check if the write triggers were already evaluated for the round of the feed, whether its report was written or skipped
+// Only the last evaluated round of every feed is kept, rounds are evaluated in order
+func (conn *PgPriceMessageRepository) IsEvaluated(ctx context.Context, feed string, round int64) (bool, error) {
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+
+	var evaluated bool
+	err := conn.db.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM evaluated_rounds WHERE feed = $1 AND round >= $2)", feed, round).Scan(&evaluated)
+	if err != nil {
+		log.Debugf("Failed to check if round %d of %s was evaluated: %v", round, feed, err)
 		return false, err
 	}
+	return evaluated, nil
+}
 
-	return true, nil
+// Store the evidence against the publisher of a report the node refused to co-sign
+// The signed report is kept as is, so the evidence can be verified against the publisher key
+func (conn *PgPriceMessageRepository) StoreEvidence(ctx context.Context, evidence *domain.Evidence) error {
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+
+	query := "INSERT INTO publisher_evidence (reporter, publisher, feed, round, epoch, message_id, proposed_price, observed_price, deviation, report, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) ON CONFLICT DO NOTHING"
+	_, err := conn.db.Exec(ctx, query, evidence.Reporter, evidence.Publisher, evidence.Feed, evidence.Round, evidence.Epoch, evidence.MessageID,
+		evidence.ProposedPrice, evidence.ObservedPrice, evidence.Deviation, evidence.Report, time.Unix(evidence.CreatedAt, 0))
+	if err != nil {
+		log.Debugf("Failed to store evidence in the database: %v", err)
+		return err
+	}
+	return nil
 }
 
 func (r *PgPriceMessageRepository) Close(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	r.db.Close(ctx)
 	return nil
 }
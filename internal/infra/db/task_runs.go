@@ -0,0 +1,70 @@
+package db
+
+// PG implementation of TaskRunRepository interface
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+
+	"chainlink-lite/internal/app/domain"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// PgTaskRunRepository stores the task runs of the jobs on a connection of its own,
+// so recording them never waits for, nor delays, the writes of the price messages
+type PgTaskRunRepository struct {
+	// A connection is not safe for concurrent use
+	mu        sync.Mutex
+	db        *pgx.Conn
+	retention time.Duration // Runs started longer ago are deleted, 0 keeps every run
+}
+
+var _ domain.TaskRunRepository = (*PgTaskRunRepository)(nil)
+
+func NewTaskRunRepository(ctx context.Context, connString string, retention time.Duration) (*PgTaskRunRepository, error) {
+	db, err := connect(ctx, connString)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PgTaskRunRepository{db: db, retention: retention}, nil
+}
+
+// Store the runs of the tasks of a job, for debugging
+// Runs older than the retention are deleted at the same time, so the table does not grow with every round
+func (conn *PgTaskRunRepository) StoreTaskRuns(ctx context.Context, runs []domain.TaskRun) error {
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+
+	batch := &pgx.Batch{}
+	query := "INSERT INTO task_runs (job, feed, round, task_id, task_type, inputs, output, error, started_at, finished_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)"
+	for _, run := range runs {
+		// The error is left NULL for successful runs
+		var runErr *string
+		if run.Error != "" {
+			runErr = &run.Error
+		}
+		batch.Queue(query, run.Job, run.Feed, run.Round, run.TaskID, run.TaskType, run.Inputs, run.Output, runErr, run.StartedAt, run.FinishedAt)
+	}
+	if conn.retention > 0 {
+		batch.Queue("DELETE FROM task_runs WHERE started_at < $1", time.Now().Add(-conn.retention))
+	}
+
+	// A batch runs in an implicit transaction
+	if err := conn.db.SendBatch(ctx, batch).Close(); err != nil {
+		log.Debugf("Failed to store task runs in the database: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *PgTaskRunRepository) Close(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.db.Close(ctx)
+	return nil
+}
@@ -0,0 +1,87 @@
+package jobs
+
+// Loads the job specs of the jobs directory, written in YAML or TOML
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"chainlink-lite/internal/app/domain"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// LoadDir reads and validates every job file of the directory, *.yaml, *.yml or *.toml, in the order of their names
+// Every job must have a distinct name and feed
+func LoadDir(dir string) ([]domain.JobSpec, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read jobs directory: %v", err)
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".yaml", ".yml", ".toml":
+			if !entry.IsDir() {
+				names = append(names, entry.Name())
+			}
+		}
+	}
+	sort.Strings(names)
+
+	specs := make([]domain.JobSpec, 0, len(names))
+	jobs := make(map[string]string, len(names))
+	feeds := make(map[string]string, len(names))
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		spec, err := Load(path)
+		if err != nil {
+			return nil, err
+		}
+		if other, ok := jobs[spec.Name]; ok {
+			return nil, fmt.Errorf("job %s is declared in %s and %s", spec.Name, other, path)
+		}
+		if other, ok := feeds[spec.Feed]; ok {
+			return nil, fmt.Errorf("feed %s is observed by jobs %s and %s", spec.Feed, other, spec.Name)
+		}
+		jobs[spec.Name] = path
+		feeds[spec.Feed] = spec.Name
+		specs = append(specs, spec)
+	}
+	return specs, nil
+}
+
+// Load reads and validates the job file at path
+func Load(path string) (domain.JobSpec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return domain.JobSpec{}, fmt.Errorf("failed to read job file: %v", err)
+	}
+
+	// TOML jobs are converted to YAML, so both formats have the same keys and task parameters
+	if strings.ToLower(filepath.Ext(path)) == ".toml" {
+		var doc map[string]interface{}
+		if err := toml.Unmarshal(data, &doc); err != nil {
+			return domain.JobSpec{}, fmt.Errorf("failed to parse job file %s: %v", path, err)
+		}
+		if data, err = yaml.Marshal(doc); err != nil {
+			return domain.JobSpec{}, fmt.Errorf("failed to convert job file %s: %v", path, err)
+		}
+	}
+
+	var spec domain.JobSpec
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&spec); err != nil {
+		return domain.JobSpec{}, fmt.Errorf("failed to parse job file %s: %v", path, err)
+	}
+	if err := spec.Validate(); err != nil {
+		return domain.JobSpec{}, fmt.Errorf("invalid job file %s: %w", path, err)
+	}
+	return spec, nil
+}
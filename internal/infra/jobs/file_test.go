@@ -0,0 +1,169 @@
+package jobs
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"chainlink-lite/internal/app/domain"
+)
+
+const yamlJob = `
+name: "link-usd"
+feed: "LINK/USD"
+topic: "oracle/link-usd"
+schedule: "30s"
+write_quorum: "3"
+decimals: 8
+tasks:
+  - id: "coinbase"
+    type: "http"
+    url: "https://api.coinbase.com/v2/prices/{{.Base}}-{{.Quote}}/spot"
+  - id: "price"
+    type: "jsonparse"
+    inputs: ["coinbase"]
+    path: "data.amount"
+  - id: "usd"
+    type: "multiply"
+    inputs: ["price"]
+    times: 1
+  - id: "sign"
+    type: "sign"
+    inputs: ["usd"]
+  - id: "gossip"
+    type: "gossip"
+    inputs: ["sign"]
+`
+
+const tomlJob = `
+name = "link-usd"
+feed = "LINK/USD"
+topic = "oracle/link-usd"
+schedule = "30s"
+write_quorum = "3"
+decimals = 8
+
+[[tasks]]
+id = "coinbase"
+type = "http"
+url = "https://api.coinbase.com/v2/prices/{{.Base}}-{{.Quote}}/spot"
+
+[[tasks]]
+id = "price"
+type = "jsonparse"
+inputs = ["coinbase"]
+path = "data.amount"
+
+[[tasks]]
+id = "usd"
+type = "multiply"
+inputs = ["price"]
+times = 1
+
+[[tasks]]
+id = "sign"
+type = "sign"
+inputs = ["usd"]
+
+[[tasks]]
+id = "gossip"
+type = "gossip"
+inputs = ["sign"]
+`
+
+// writeJobs writes the job files into a new directory, by file name, and returns the directory
+func writeJobs(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	return dir
+}
+
+func TestLoadTOMLAsYAML(t *testing.T) {
+	dir := writeJobs(t, map[string]string{"link-usd.yaml": yamlJob, "link-usd.toml": tomlJob})
+	fromYAML, err := Load(filepath.Join(dir, "link-usd.yaml"))
+	if err != nil {
+		t.Fatalf("Load YAML: %v", err)
+	}
+	fromTOML, err := Load(filepath.Join(dir, "link-usd.toml"))
+	if err != nil {
+		t.Fatalf("Load TOML: %v", err)
+	}
+	if fromYAML.Schedule.String() != "30s" || fromYAML.Decimals == nil || *fromYAML.Decimals != 8 || len(fromYAML.Tasks) != 5 {
+		t.Errorf("Load YAML = %+v", fromYAML)
+	}
+	// The task parameters are kept as their types may differ, e.g. TOML integers, and are decoded by the tasks
+	for i := range fromTOML.Tasks {
+		fromYAML.Tasks[i].Params, fromTOML.Tasks[i].Params = nil, nil
+	}
+	if !reflect.DeepEqual(fromTOML, fromYAML) {
+		t.Errorf("Load TOML = %+v, want %+v", fromTOML, fromYAML)
+	}
+}
+
+func TestLoadRejectsInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{name: "unknown key", file: "job.yaml", content: yamlJob + "retries: 3\n"},
+		{name: "unknown TOML key", file: "job.toml", content: "retries = 3\n" + tomlJob},
+		{name: "no decimals", file: "job.yaml", content: strings.Replace(yamlJob, "decimals: 8\n", "", 1)},
+		{name: "invalid YAML", file: "job.yaml", content: "name: [link-usd\n"},
+		{name: "invalid TOML", file: "job.toml", content: "name = link-usd\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeJobs(t, map[string]string{tt.file: tt.content})
+			if _, err := Load(filepath.Join(dir, tt.file)); err == nil {
+				t.Errorf("Load succeeded")
+			}
+		})
+	}
+
+	// Invalid pipelines are reported as invalid jobs
+	dir := writeJobs(t, map[string]string{"job.yaml": strings.Replace(yamlJob, `inputs: ["usd"]`, `inputs: ["price"]`, 1)})
+	if _, err := Load(filepath.Join(dir, "job.yaml")); !errors.Is(err, domain.ErrInvalidJob) {
+		t.Errorf("Load of an unused task = %v, want %v", err, domain.ErrInvalidJob)
+	}
+}
+
+func TestLoadDir(t *testing.T) {
+	other := strings.NewReplacer(`"link-usd"`, `"btc-usd"`, `"LINK/USD"`, `"BTC/USD"`, `"oracle/link-usd"`, `"oracle/btc-usd"`).Replace(yamlJob)
+	dir := writeJobs(t, map[string]string{
+		"b.yml":     other,
+		"a.toml":    tomlJob,
+		"notes.txt": "not a job",
+	})
+	if err := os.Mkdir(filepath.Join(dir, "c.yaml"), 0o700); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	specs, err := LoadDir(dir)
+	if err != nil {
+		t.Fatalf("LoadDir: %v", err)
+	}
+	if len(specs) != 2 || specs[0].Name != "link-usd" || specs[1].Name != "btc-usd" {
+		t.Errorf("LoadDir = %+v, want link-usd and btc-usd in file order", specs)
+	}
+
+	// Two jobs can not share a name nor a feed
+	renamed := strings.Replace(yamlJob, `name: "link-usd"`, `name: "link-usd-2"`, 1)
+	for name, files := range map[string]map[string]string{
+		"same name": {"a.toml": tomlJob, "b.yaml": strings.Replace(other, `"btc-usd"`, `"link-usd"`, 1)},
+		"same feed": {"a.toml": tomlJob, "b.yaml": renamed},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := LoadDir(writeJobs(t, files)); err == nil {
+				t.Errorf("LoadDir succeeded")
+			}
+		})
+	}
+}
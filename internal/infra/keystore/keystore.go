@@ -0,0 +1,253 @@
+package keystore
+
+// Password encrypted on-disk storage of the node private key
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	keystoreVersion = 1
+	kdfName         = "scrypt"
+	cipherName      = "aes-256-gcm"
+
+	// scrypt parameters used for new keystores, existing keystores keep the parameters they were created with
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	scryptKeyLen = 32
+	saltLen      = 32
+
+	// Bounds of the scrypt cost read from a keystore, so a tampered file can not make the node allocate gigabytes
+	minScryptN = 1 << 14
+	maxScryptN = 1 << 18
+)
+
+// ErrCorruptKeystore is returned when the keystore file can not be parsed or does not hold a valid key.
+var ErrCorruptKeystore = errors.New("corrupt keystore")
+
+// ErrWrongPassphrase is returned when the keystore can not be decrypted with the given passphrase.
+var ErrWrongPassphrase = errors.New("wrong keystore passphrase")
+
+// ErrNoPassphrase is returned when no passphrase is configured for the keystore.
+var ErrNoPassphrase = errors.New("no keystore passphrase configured")
+
+type keystoreFile struct {
+	Version    int       `json:"version"`
+	PeerID     string    `json:"peer_id"`
+	KDF        string    `json:"kdf"`
+	KDFParams  kdfParams `json:"kdf_params"`
+	Cipher     string    `json:"cipher"`
+	Nonce      string    `json:"nonce"`
+	Ciphertext string    `json:"ciphertext"`
+}
+
+type kdfParams struct {
+	N      int    `json:"n"`
+	R      int    `json:"r"`
+	P      int    `json:"p"`
+	KeyLen int    `json:"key_len"`
+	Salt   string `json:"salt"`
+}
+
+// LoadOrCreate loads the private key from the keystore at path
+// If the keystore does not exist, a new key of keyType is generated and stored there
+func LoadOrCreate(path string, passphrase string, keyType int) (crypto.PrivKey, error) {
+	key, err := Load(path, passphrase)
+	if err == nil || !errors.Is(err, os.ErrNotExist) {
+		return key, err
+	}
+
+	log.Infof("No keystore found at %s, generating a new node key", path)
+	key, _, err = crypto.GenerateKeyPair(keyType, -1)
+	if err != nil {
+		return nil, err
+	}
+	if err := Save(path, passphrase, key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// Load decrypts the private key stored in the keystore at path
+func Load(path string, passphrase string) (crypto.PrivKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var ks keystoreFile
+	if err := json.Unmarshal(data, &ks); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCorruptKeystore, err)
+	}
+	if ks.Version != keystoreVersion || ks.KDF != kdfName || ks.Cipher != cipherName {
+		return nil, fmt.Errorf("%w: unsupported version %d, kdf %q or cipher %q", ErrCorruptKeystore, ks.Version, ks.KDF, ks.Cipher)
+	}
+
+	if err := checkKDFParams(ks.KDFParams); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCorruptKeystore, err)
+	}
+	salt, err := hex.DecodeString(ks.KDFParams.Salt)
+	if err != nil || len(salt) != saltLen {
+		return nil, fmt.Errorf("%w: invalid salt", ErrCorruptKeystore)
+	}
+	nonce, err := hex.DecodeString(ks.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid nonce: %v", ErrCorruptKeystore, err)
+	}
+	ciphertext, err := hex.DecodeString(ks.Ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid ciphertext: %v", ErrCorruptKeystore, err)
+	}
+
+	aead, err := newAEAD(passphrase, salt, ks.KDFParams)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCorruptKeystore, err)
+	}
+	if len(nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("%w: invalid nonce length %d", ErrCorruptKeystore, len(nonce))
+	}
+
+	plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(ks.PeerID))
+	if err != nil {
+		// GCM can not tell a wrong passphrase from a tampered file
+		return nil, fmt.Errorf("%w or %w", ErrWrongPassphrase, ErrCorruptKeystore)
+	}
+
+	key, err := crypto.UnmarshalPrivateKey(plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCorruptKeystore, err)
+	}
+
+	id, err := peer.IDFromPrivateKey(key)
+	if err != nil || id.String() != ks.PeerID {
+		return nil, fmt.Errorf("%w: key does not match peer ID %s", ErrCorruptKeystore, ks.PeerID)
+	}
+
+	return key, nil
+}
+
+// Save encrypts the private key with the passphrase and writes it to the keystore at path
+// The file is written atomically and is only readable by the current user
+func Save(path string, passphrase string, key crypto.PrivKey) error {
+	if passphrase == "" {
+		return ErrNoPassphrase
+	}
+
+	id, err := peer.IDFromPrivateKey(key)
+	if err != nil {
+		return err
+	}
+	plaintext, err := crypto.MarshalPrivateKey(key)
+	if err != nil {
+		return err
+	}
+
+	salt := make([]byte, saltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	params := kdfParams{N: scryptN, R: scryptR, P: scryptP, KeyLen: scryptKeyLen, Salt: hex.EncodeToString(salt)}
+
+	aead, err := newAEAD(passphrase, salt, params)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(keystoreFile{
+		Version:    keystoreVersion,
+		PeerID:     id.String(),
+		KDF:        kdfName,
+		KDFParams:  params,
+		Cipher:     cipherName,
+		Nonce:      hex.EncodeToString(nonce),
+		Ciphertext: hex.EncodeToString(aead.Seal(nil, nonce, plaintext, []byte(id.String()))),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return writeFile(path, data)
+}
+
+// ReadPassphrase returns the keystore passphrase from passphraseFile if set, or from the passphraseEnv environment variable
+func ReadPassphrase(passphraseEnv string, passphraseFile string) (string, error) {
+	var passphrase string
+	if passphraseFile != "" {
+		data, err := os.ReadFile(passphraseFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read passphrase file: %v", err)
+		}
+		passphrase = strings.TrimRight(string(data), "\r\n")
+	} else if passphraseEnv != "" {
+		passphrase = os.Getenv(passphraseEnv)
+	}
+
+	if passphrase == "" {
+		return "", ErrNoPassphrase
+	}
+	return passphrase, nil
+}
+
+// writeFile atomically replaces the file at path with data, only readable by the current user
+func writeFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create keystore directory: %v", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %v", path, err)
+	}
+	defer os.Remove(tmp.Name()) //nolint:all
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %v", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %v", path, err)
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// checkKDFParams only accepts the scrypt parameters this version writes, with a cost N within the bounds
+func checkKDFParams(params kdfParams) error {
+	if params.N < minScryptN || params.N > maxScryptN || params.N&(params.N-1) != 0 {
+		return fmt.Errorf("unsupported scrypt cost N=%d", params.N)
+	}
+	if params.R != scryptR || params.P != scryptP || params.KeyLen != scryptKeyLen {
+		return fmt.Errorf("unsupported scrypt parameters r=%d, p=%d, key length %d", params.R, params.P, params.KeyLen)
+	}
+	return nil
+}
+
+// newAEAD derives the encryption key from the passphrase and returns the AES-GCM cipher
+func newAEAD(passphrase string, salt []byte, params kdfParams) (cipher.AEAD, error) {
+	derived, err := scrypt.Key([]byte(passphrase), salt, params.N, params.R, params.P, params.KeyLen)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(derived)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,147 @@
+package keystore
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+const testPassphrase = "passphrase"
+
+// savedKeystore saves a new key in a keystore, and returns the path of the keystore and the key
+func savedKeystore(t *testing.T) (string, crypto.PrivKey) {
+	t.Helper()
+	key, _, err := crypto.GenerateEd25519Key(nil)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "node.json")
+	if err := Save(path, testPassphrase, key); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	return path, key
+}
+
+// tamper rewrites the keystore at path after modifying its decoded file
+func tamper(t *testing.T, path string, modify func(ks *keystoreFile)) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read keystore: %v", err)
+	}
+	var ks keystoreFile
+	if err := json.Unmarshal(data, &ks); err != nil {
+		t.Fatalf("decode keystore: %v", err)
+	}
+	modify(&ks)
+	if data, err = json.Marshal(ks); err != nil {
+		t.Fatalf("encode keystore: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("write keystore: %v", err)
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	path, key := savedKeystore(t)
+	loaded, err := Load(path, testPassphrase)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if !loaded.Equals(key) {
+		t.Errorf("Load returned another key")
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("keystore permissions = %o, want 600", perm)
+	}
+
+	if err := Save(path, "", key); !errors.Is(err, ErrNoPassphrase) {
+		t.Errorf("Save without a passphrase = %v, want %v", err, ErrNoPassphrase)
+	}
+}
+
+func TestLoadWrongPassphrase(t *testing.T) {
+	path, _ := savedKeystore(t)
+	if _, err := Load(path, "wrong"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("Load = %v, want %v", err, ErrWrongPassphrase)
+	}
+}
+
+func TestLoadRejectsTampered(t *testing.T) {
+	other, _, err := crypto.GenerateEd25519Key(nil)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	otherID, err := peer.IDFromPrivateKey(other)
+	if err != nil {
+		t.Fatalf("peer ID: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(ks *keystoreFile)
+	}{
+		{name: "ciphertext", modify: func(ks *keystoreFile) {
+			ciphertext, _ := hex.DecodeString(ks.Ciphertext)
+			ciphertext[0] ^= 0x01
+			ks.Ciphertext = hex.EncodeToString(ciphertext)
+		}},
+		{name: "peer ID", modify: func(ks *keystoreFile) { ks.PeerID = otherID.String() }},
+		{name: "nonce", modify: func(ks *keystoreFile) { ks.Nonce = ks.Nonce[:len(ks.Nonce)-2] }},
+		{name: "salt", modify: func(ks *keystoreFile) { ks.KDFParams.Salt = ks.KDFParams.Salt[:2] }},
+		{name: "version", modify: func(ks *keystoreFile) { ks.Version = 2 }},
+		{name: "huge scrypt cost", modify: func(ks *keystoreFile) { ks.KDFParams.N = 1 << 30 }},
+		{name: "scrypt cost not a power of two", modify: func(ks *keystoreFile) { ks.KDFParams.N = 1<<15 + 1 }},
+		{name: "scrypt block size", modify: func(ks *keystoreFile) { ks.KDFParams.R = 1 << 20 }},
+		{name: "scrypt parallelism", modify: func(ks *keystoreFile) { ks.KDFParams.P = 1 << 20 }},
+		{name: "key length", modify: func(ks *keystoreFile) { ks.KDFParams.KeyLen = 1 << 30 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, _ := savedKeystore(t)
+			tamper(t, path, tt.modify)
+			if _, err := Load(path, testPassphrase); !errors.Is(err, ErrCorruptKeystore) {
+				t.Errorf("Load = %v, want %v", err, ErrCorruptKeystore)
+			}
+		})
+	}
+}
+
+func TestLoadOrCreate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keystore", "node.json")
+	if _, err := Load(path, testPassphrase); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("Load of a missing keystore = %v, want %v", err, os.ErrNotExist)
+	}
+
+	// The key is created on first run, and loaded on the next runs
+	created, err := LoadOrCreate(path, testPassphrase, crypto.Ed25519)
+	if err != nil {
+		t.Fatalf("LoadOrCreate on first run: %v", err)
+	}
+	loaded, err := LoadOrCreate(path, testPassphrase, crypto.Ed25519)
+	if err != nil {
+		t.Fatalf("LoadOrCreate on next run: %v", err)
+	}
+	if !loaded.Equals(created) {
+		t.Errorf("LoadOrCreate created another key on the next run")
+	}
+
+	// An existing keystore is never replaced
+	if _, err := LoadOrCreate(path, "wrong", crypto.Ed25519); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("LoadOrCreate with a wrong passphrase = %v, want %v", err, ErrWrongPassphrase)
+	}
+	if again, err := Load(path, testPassphrase); err != nil || !again.Equals(created) {
+		t.Errorf("Load after a wrong passphrase = %v, want the created key", err)
+	}
+}
@@ -0,0 +1,44 @@
+package keystore
+
+// Stores the key rotation announcements of the node next to its keystore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"chainlink-lite/internal/app/domain"
+)
+
+// LoadRotations reads the key rotation announcements stored at path, oldest first
+// Returns no announcement if the file does not exist
+func LoadRotations(path string) ([]domain.KeyRotation, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key rotations: %v", err)
+	}
+
+	var rotations []domain.KeyRotation
+	if err := json.Unmarshal(data, &rotations); err != nil {
+		return nil, fmt.Errorf("failed to parse key rotations %s: %v", path, err)
+	}
+	return rotations, nil
+}
+
+// AppendRotation adds the key rotation announcement to the announcements stored at path
+func AppendRotation(path string, rotation domain.KeyRotation) error {
+	rotations, err := LoadRotations(path)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(append(rotations, rotation), "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFile(path, data)
+}
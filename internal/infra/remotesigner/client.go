@@ -0,0 +1,176 @@
+package remotesigner
+
+// HTTP client implementation of the domain.Signer interface, backed by a separate signer process
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"chainlink-lite/internal/app/domain"
+	"chainlink-lite/internal/app/service"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type RemoteSigner struct {
+	url       string
+	token     string
+	client    *http.Client
+	id        string
+	publicKey string
+}
+
+var _ domain.Signer = (*RemoteSigner)(nil)
+
+type identityResponse struct {
+	ID        string `json:"id"`
+	PublicKey string `json:"public_key"`
+}
+
+type signRequest struct {
+	Report *domain.PriceMessage `json:"report"`
+}
+
+type signObservationRequest struct {
+	Observation *domain.Observation `json:"observation"`
+}
+
+type signCommitmentRequest struct {
+	Commitment *domain.Commitment `json:"commitment"`
+}
+
+type signResponse struct {
+	Signature string `json:"signature"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// NewRemoteSigner connects to the signer server at url with the bearer token, and fetches the identity it signs for
+func NewRemoteSigner(ctx context.Context, url string, token string, timeout time.Duration) (*RemoteSigner, error) {
+	r := &RemoteSigner{
+		url:    strings.TrimRight(url, "/"),
+		token:  token,
+		client: &http.Client{Timeout: timeout},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.url+identityPath, nil)
+	if err != nil {
+		return nil, err
+	}
+	var identity identityResponse
+	if err := r.do(req, &identity); err != nil {
+		return nil, fmt.Errorf("failed to fetch remote signer identity: %v", err)
+	}
+
+	// The identity must be derived from the public key, or none of the signatures would be attributable
+	if _, err := service.VerifyIdentity(identity.ID, identity.PublicKey); err != nil {
+		return nil, fmt.Errorf("invalid remote signer identity: %v", err)
+	}
+
+	r.id = identity.ID
+	r.publicKey = identity.PublicKey
+	log.Infof("Using remote signer %s at %s", r.id, r.url)
+	return r, nil
+}
+
+// SignReport sends the report to the signer server, which applies its policy and signs the canonical payload
+func (r *RemoteSigner) SignReport(ctx context.Context, report *domain.PriceMessage) (string, error) {
+	signature, err := r.sign(ctx, signPath, signRequest{Report: report})
+	if err != nil {
+		return "", err
+	}
+
+	// Never hand out a signature the rest of the network would reject
+	if err := service.VerifyReportSignature(report, r.id, r.publicKey, signature); err != nil {
+		return "", fmt.Errorf("remote signer returned an invalid signature: %v", err)
+	}
+	return signature, nil
+}
+
+// SignObservation sends the observation to the signer server, which applies its policy and signs the canonical payload
+func (r *RemoteSigner) SignObservation(ctx context.Context, observation *domain.Observation) (string, error) {
+	signature, err := r.sign(ctx, signObservationPath, signObservationRequest{Observation: observation})
+	if err != nil {
+		return "", err
+	}
+
+	signed := *observation
+	signed.Signature = signature
+	if err := service.VerifyObservation(&signed); err != nil {
+		return "", fmt.Errorf("remote signer returned an invalid signature: %v", err)
+	}
+	return signature, nil
+}
+
+// SignCommitment sends the commitment to the signer server, which signs the canonical payload
+func (r *RemoteSigner) SignCommitment(ctx context.Context, commitment *domain.Commitment) (string, error) {
+	signature, err := r.sign(ctx, signCommitmentPath, signCommitmentRequest{Commitment: commitment})
+	if err != nil {
+		return "", err
+	}
+
+	signed := *commitment
+	signed.Signature = signature
+	if err := service.VerifyCommitment(&signed); err != nil {
+		return "", fmt.Errorf("remote signer returned an invalid signature: %v", err)
+	}
+	return signature, nil
+}
+
+// ID returns the node ID of the remote signing key
+func (r *RemoteSigner) ID() string {
+	return r.id
+}
+
+// PublicKey returns the hex encoded public key of the remote signing key
+func (r *RemoteSigner) PublicKey() string {
+	return r.publicKey
+}
+
+// sign posts the signing request to the path and returns the signature
+func (r *RemoteSigner) sign(ctx context.Context, path string, request interface{}) (string, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.url+path, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	var resp signResponse
+	if err := r.do(req, &resp); err != nil {
+		return "", err
+	}
+	return resp.Signature, nil
+}
+
+// do sends the request and decodes the JSON response into out
+// Policy refusals are returned as domain.ErrSigningRefused
+func (r *RemoteSigner) do(req *http.Request, out interface{}) error {
+	req.Header.Set("Authorization", "Bearer "+r.token)
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var errResp errorResponse
+		_ = json.NewDecoder(resp.Body).Decode(&errResp)
+		if resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests {
+			return fmt.Errorf("%w: %s", domain.ErrSigningRefused, errResp.Error)
+		}
+		return fmt.Errorf("remote signer responded with status %d: %s", resp.StatusCode, errResp.Error)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,390 @@
+package remotesigner
+
+// Reference signer server, holding the signing key in place of a KMS or HSM
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"chainlink-lite/internal/app/domain"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	identityPath        = "/v1/identity"
+	signPath            = "/v1/sign"
+	signObservationPath = "/v1/sign-observation"
+	signCommitmentPath  = "/v1/sign-commitment"
+)
+
+// ErrNoToken is returned when no bearer token is configured for the signer server
+var ErrNoToken = errors.New("no signer token configured")
+
+// bucketIdleTime is the time after which the bucket of an idle client is full again, and can be dropped
+const bucketIdleTime = time.Minute
+
+// Policy is enforced by the signer server on every signing request, whatever the node asks for
+// RateLimit is the maximum number of signatures per minute of every client, 0 disables the limit
+// Bounds are the prices accepted for each feed, by feed
+// MaxDeviation is the maximum relative change from the last price signed for the same feed, 0 disables the check.
+// The last price only bounds the deviation for DeviationWindow, so the signer follows the market after a large move
+type Policy struct {
+	RateLimit       int
+	Bounds          map[string]PriceBounds
+	MaxDeviation    float64
+	DeviationWindow time.Duration
+}
+
+// PriceBounds bound the prices of a feed, a zero bound is disabled
+type PriceBounds struct {
+	Min domain.Price
+	Max domain.Price
+}
+
+type Server struct {
+	signer domain.Signer
+	policy Policy
+	token  string
+	now    func() time.Time
+
+	mu         sync.Mutex
+	buckets    map[string]*bucket // by client address
+	lastSweep  time.Time
+	lastPrices map[string]signedPrice // by feed
+}
+
+// bucket is the rate limiter bucket of a client
+type bucket struct {
+	tokens     float64
+	lastRefill time.Time
+}
+
+// signedPrice is the last price signed for a feed
+type signedPrice struct {
+	price    domain.Price
+	signedAt time.Time
+}
+
+// NewServer creates the signer server, only serving the requests that present the bearer token
+func NewServer(signer domain.Signer, policy Policy, token string) (*Server, error) {
+	if token == "" {
+		return nil, ErrNoToken
+	}
+	if policy.MaxDeviation > 0 && policy.DeviationWindow <= 0 {
+		return nil, errors.New("the maximum deviation requires a deviation window")
+	}
+	for feed, bounds := range policy.Bounds {
+		if !bounds.Min.IsZero() && !bounds.Max.IsZero() && bounds.Min.Cmp(bounds.Max) > 0 {
+			return nil, fmt.Errorf("minimum price %s of %s above maximum %s", bounds.Min, feed, bounds.Max)
+		}
+	}
+	return &Server{
+		signer:     signer,
+		policy:     policy,
+		token:      token,
+		now:        time.Now,
+		buckets:    make(map[string]*bucket),
+		lastPrices: make(map[string]signedPrice),
+	}, nil
+}
+
+// Handler returns the HTTP handler serving the signer API
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(identityPath, s.handleIdentity)
+	mux.HandleFunc(signPath, s.handleSign)
+	mux.HandleFunc(signObservationPath, s.handleSignObservation)
+	mux.HandleFunc(signCommitmentPath, s.handleSignCommitment)
+	return s.authenticate(mux)
+}
+
+// authenticate refuses the requests that do not present the bearer token of the server
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			log.Warnf("Refusing unauthenticated request from %s", r.RemoteAddr)
+			writeError(w, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ReadToken reads the bearer token of the signer server from the file, or else from the environment variable
+func ReadToken(tokenEnv string, tokenFile string) (string, error) {
+	var token string
+	if tokenFile != "" {
+		data, err := os.ReadFile(tokenFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read token file: %v", err)
+		}
+		token = strings.TrimSpace(string(data))
+	} else if tokenEnv != "" {
+		token = os.Getenv(tokenEnv)
+	}
+
+	if token == "" {
+		return "", ErrNoToken
+	}
+	return token, nil
+}
+
+func (s *Server) handleIdentity(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	writeJSON(w, http.StatusOK, identityResponse{ID: s.signer.ID(), PublicKey: s.signer.PublicKey()})
+}
+
+func (s *Server) handleSign(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req signRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<16)).Decode(&req); err != nil || req.Report == nil {
+		writeError(w, http.StatusBadRequest, "invalid signing request")
+		return
+	}
+	report := req.Report
+
+	if !s.allow(clientOf(r)) {
+		log.Warnf("Rate limit of %s exceeded, refusing to sign report %s", clientOf(r), report.MessageID)
+		writeError(w, http.StatusTooManyRequests, "rate limit exceeded")
+		return
+	}
+
+	if err := s.checkPolicy(report); err != nil {
+		log.Warnf("Refusing to sign report %s: %v", report.MessageID, err)
+		writeError(w, http.StatusForbidden, err.Error())
+		return
+	}
+
+	signature, err := s.signer.SignReport(r.Context(), report)
+	if err != nil {
+		log.Warnf("Failed to sign report %s: %v", report.MessageID, err)
+		writeError(w, http.StatusInternalServerError, "failed to sign report")
+		return
+	}
+
+	s.signed(report.Feed, report.Price)
+
+	log.Infof("Signed report %s: %s %s", report.MessageID, report.Feed, report.Price)
+	writeJSON(w, http.StatusOK, signResponse{Signature: signature})
+}
+
+func (s *Server) handleSignObservation(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req signObservationRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<16)).Decode(&req); err != nil || req.Observation == nil {
+		writeError(w, http.StatusBadRequest, "invalid signing request")
+		return
+	}
+	observation := req.Observation
+
+	if !s.allow(clientOf(r)) {
+		log.Warnf("Rate limit of %s exceeded, refusing to sign observation of round %d", clientOf(r), observation.Round)
+		writeError(w, http.StatusTooManyRequests, "rate limit exceeded")
+		return
+	}
+
+	if err := s.checkObservationPolicy(observation); err != nil {
+		log.Warnf("Refusing to sign observation of round %d: %v", observation.Round, err)
+		writeError(w, http.StatusForbidden, err.Error())
+		return
+	}
+
+	signature, err := s.signer.SignObservation(r.Context(), observation)
+	if err != nil {
+		log.Warnf("Failed to sign observation of round %d: %v", observation.Round, err)
+		writeError(w, http.StatusInternalServerError, "failed to sign observation")
+		return
+	}
+
+	s.signed(observation.Feed, observation.Price)
+
+	log.Infof("Signed observation of round %d: %s %s", observation.Round, observation.Feed, observation.Price)
+	writeJSON(w, http.StatusOK, signResponse{Signature: signature})
+}
+
+// handleSignCommitment signs the commitment to an observation
+// The price is hidden until the observation is revealed, so the price policy applies to the observation only
+func (s *Server) handleSignCommitment(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req signCommitmentRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<16)).Decode(&req); err != nil || req.Commitment == nil {
+		writeError(w, http.StatusBadRequest, "invalid signing request")
+		return
+	}
+	commitment := req.Commitment
+
+	if !s.allow(clientOf(r)) {
+		log.Warnf("Rate limit of %s exceeded, refusing to sign commitment of round %d", clientOf(r), commitment.Round)
+		writeError(w, http.StatusTooManyRequests, "rate limit exceeded")
+		return
+	}
+
+	if err := s.checkCommitmentPolicy(commitment); err != nil {
+		log.Warnf("Refusing to sign commitment of round %d: %v", commitment.Round, err)
+		writeError(w, http.StatusForbidden, err.Error())
+		return
+	}
+
+	signature, err := s.signer.SignCommitment(r.Context(), commitment)
+	if err != nil {
+		log.Warnf("Failed to sign commitment of round %d: %v", commitment.Round, err)
+		writeError(w, http.StatusInternalServerError, "failed to sign commitment")
+		return
+	}
+
+	log.Infof("Signed commitment of round %d: %s %s", commitment.Round, commitment.Feed, commitment.Digest)
+	writeJSON(w, http.StatusOK, signResponse{Signature: signature})
+}
+
+// allow takes a token from the rate limiter bucket of the client, which refills continuously up to RateLimit tokens per minute
+// Every client has its own bucket, so a client can not use up the signing budget of the others
+func (s *Server) allow(client string) bool {
+	if s.policy.RateLimit <= 0 {
+		return true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+	limit := float64(s.policy.RateLimit)
+	b, ok := s.buckets[client]
+	if !ok {
+		b = &bucket{tokens: limit, lastRefill: now}
+		s.buckets[client] = b
+	}
+	b.tokens = math.Min(limit, b.tokens+now.Sub(b.lastRefill).Minutes()*limit)
+	b.lastRefill = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// sweep drops the buckets of the clients idle long enough for their bucket to be full, at most once per idle time,
+// as a new bucket is full as well
+func (s *Server) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < bucketIdleTime {
+		return
+	}
+	s.lastSweep = now
+	for client, b := range s.buckets {
+		if now.Sub(b.lastRefill) >= bucketIdleTime {
+			delete(s.buckets, client)
+		}
+	}
+}
+
+// clientOf returns the address of the client of the request, without its port
+func clientOf(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// checkPolicy checks that the report is complete and that its price is sane
+func (s *Server) checkPolicy(report *domain.PriceMessage) error {
+	if report.MessageID == "" || report.Feed == "" || report.Publisher == "" || report.CreatedAt == 0 {
+		return fmt.Errorf("incomplete report")
+	}
+	return s.checkPrice(report.Feed, report.Price)
+}
+
+// checkObservationPolicy checks that the observation is attributed to the signer and that its price is sane
+func (s *Server) checkObservationPolicy(observation *domain.Observation) error {
+	if observation.Round == 0 || observation.Feed == "" || observation.CreatedAt == 0 {
+		return fmt.Errorf("incomplete observation")
+	}
+	if observation.Observer != s.signer.ID() {
+		return fmt.Errorf("observation is attributed to %s", observation.Observer)
+	}
+	return s.checkPrice(observation.Feed, observation.Price)
+}
+
+// checkCommitmentPolicy checks that the commitment is complete and attributed to the signer
+func (s *Server) checkCommitmentPolicy(commitment *domain.Commitment) error {
+	if commitment.Round == 0 || commitment.Feed == "" || commitment.Digest == "" || commitment.CreatedAt == 0 {
+		return fmt.Errorf("incomplete commitment")
+	}
+	if commitment.Observer != s.signer.ID() {
+		return fmt.Errorf("commitment is attributed to %s", commitment.Observer)
+	}
+	return nil
+}
+
+// checkPrice checks the price against the bounds of the feed and the last price signed for the feed
+func (s *Server) checkPrice(feed string, price domain.Price) error {
+	if price.Sign() <= 0 {
+		return fmt.Errorf("invalid price %q", price.String())
+	}
+	bounds := s.policy.Bounds[feed]
+	if !bounds.Min.IsZero() && price.Cmp(bounds.Min) < 0 {
+		return fmt.Errorf("price %s below minimum %s", price, bounds.Min)
+	}
+	if !bounds.Max.IsZero() && price.Cmp(bounds.Max) > 0 {
+		return fmt.Errorf("price %s above maximum %s", price, bounds.Max)
+	}
+
+	if s.policy.MaxDeviation > 0 {
+		s.mu.Lock()
+		last, ok := s.lastPrices[feed]
+		s.mu.Unlock()
+		if !ok || s.now().Sub(last.signedAt) > s.policy.DeviationWindow {
+			return nil
+		}
+		// Deviation is in percent, the policy is a fraction
+		if deviation, err := domain.Deviation(price, last.price); err == nil && deviation/100 > s.policy.MaxDeviation {
+			return fmt.Errorf("price %s deviates more than %v from last signed price %s", price, s.policy.MaxDeviation, last.price)
+		}
+	}
+	return nil
+}
+
+// signed records the price signed for the feed, as the reference of the deviation of the next prices
+func (s *Server) signed(feed string, price domain.Price) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastPrices[feed] = signedPrice{price: price, signedAt: s.now()}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Debugf("Failed to write response: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, errorResponse{Error: msg})
+}
@@ -0,0 +1,261 @@
+package remotesigner
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"chainlink-lite/internal/app/domain"
+	"chainlink-lite/internal/app/service"
+
+	"github.com/libp2p/go-libp2p/core/crypto"
+)
+
+const testToken = "secret"
+
+// testClock is a clock the tests move forward
+type testClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *testClock) Add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// newTestSigner returns a signer with a new key
+func newTestSigner(t *testing.T) *service.SignerService {
+	t.Helper()
+	key, _, err := crypto.GenerateEd25519Key(nil)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	signer, err := service.NewSignerService(key, domain.SchemeEd25519)
+	if err != nil {
+		t.Fatalf("NewSignerService: %v", err)
+	}
+	return signer
+}
+
+// newTestServer starts a signer server with the policy, and returns its clock and a client of it
+func newTestServer(t *testing.T, policy Policy) (*Server, *testClock, *RemoteSigner) {
+	t.Helper()
+	server, err := NewServer(newTestSigner(t), policy, testToken)
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	clock := &testClock{now: time.Now()}
+	server.now = clock.Now
+	httpServer := httptest.NewServer(server.Handler())
+	t.Cleanup(httpServer.Close)
+
+	client, err := NewRemoteSigner(context.Background(), httpServer.URL, testToken, 5*time.Second)
+	if err != nil {
+		t.Fatalf("NewRemoteSigner: %v", err)
+	}
+	return server, clock, client
+}
+
+// testReport returns a report of the feed at the price
+func testReport(t *testing.T, feed string, price string) *domain.PriceMessage {
+	t.Helper()
+	p, err := domain.ParsePrice(price)
+	if err != nil {
+		t.Fatalf("ParsePrice(%q): %v", price, err)
+	}
+	return &domain.PriceMessage{
+		MessageID: "report-" + price,
+		Feed:      feed,
+		Round:     1,
+		Price:     p,
+		Publisher: "publisher",
+		CreatedAt: time.Now().Unix(),
+	}
+}
+
+func TestServerAuthenticates(t *testing.T) {
+	server, err := NewServer(newTestSigner(t), Policy{}, testToken)
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	httpServer := httptest.NewServer(server.Handler())
+	defer httpServer.Close()
+
+	for _, header := range []string{"", "Bearer", "Bearer wrong", "Basic " + testToken, testToken} {
+		req, err := http.NewRequest(http.MethodGet, httpServer.URL+identityPath, nil)
+		if err != nil {
+			t.Fatalf("NewRequest: %v", err)
+		}
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("request: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("status with Authorization %q = %d, want %d", header, resp.StatusCode, http.StatusUnauthorized)
+		}
+	}
+
+	if _, err := NewRemoteSigner(context.Background(), httpServer.URL, "wrong", 5*time.Second); err == nil {
+		t.Errorf("NewRemoteSigner with a wrong token succeeded")
+	}
+	if _, err := NewServer(newTestSigner(t), Policy{}, ""); !errors.Is(err, ErrNoToken) {
+		t.Errorf("NewServer without a token = %v, want %v", err, ErrNoToken)
+	}
+}
+
+func TestServerRateLimit(t *testing.T) {
+	server, clock, client := newTestServer(t, Policy{RateLimit: 2})
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.SignReport(ctx, testReport(t, "ETH/USD", "3456.78")); err != nil {
+			t.Fatalf("SignReport %d: %v", i, err)
+		}
+	}
+	if _, err := client.SignReport(ctx, testReport(t, "ETH/USD", "3456.78")); !errors.Is(err, domain.ErrSigningRefused) {
+		t.Errorf("SignReport beyond the rate limit = %v, want %v", err, domain.ErrSigningRefused)
+	}
+
+	// The bucket refills over a minute
+	clock.Add(30 * time.Second)
+	if _, err := client.SignReport(ctx, testReport(t, "ETH/USD", "3456.78")); err != nil {
+		t.Errorf("SignReport after refilling: %v", err)
+	}
+
+	// The buckets of idle clients are dropped
+	server.allow("10.0.0.1")
+	clock.Add(bucketIdleTime)
+	server.allow("10.0.0.2")
+	server.mu.Lock()
+	_, idle := server.buckets["10.0.0.1"]
+	count := len(server.buckets)
+	server.mu.Unlock()
+	if idle || count != 1 {
+		t.Errorf("%d buckets after the clients were idle, want the bucket of the last client only", count)
+	}
+}
+
+func TestServerPriceBounds(t *testing.T) {
+	low, err := domain.ParseDecimal("1000")
+	if err != nil {
+		t.Fatalf("ParseDecimal: %v", err)
+	}
+	high, err := domain.ParseDecimal("10000.5")
+	if err != nil {
+		t.Fatalf("ParseDecimal: %v", err)
+	}
+	_, _, client := newTestServer(t, Policy{Bounds: map[string]PriceBounds{"ETH/USD": {Min: low, Max: high}}})
+
+	tests := []struct {
+		feed  string
+		price string
+		valid bool
+	}{
+		{feed: "ETH/USD", price: "3456.78", valid: true},
+		{feed: "ETH/USD", price: "1000.00", valid: true},
+		{feed: "ETH/USD", price: "10000.50000001"},
+		{feed: "ETH/USD", price: "999.99999999"},
+		{feed: "BTC/USD", price: "65000.00", valid: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.feed+" "+tt.price, func(t *testing.T) {
+			_, err := client.SignReport(context.Background(), testReport(t, tt.feed, tt.price))
+			if tt.valid && err != nil {
+				t.Errorf("SignReport: %v", err)
+			}
+			if !tt.valid && !errors.Is(err, domain.ErrSigningRefused) {
+				t.Errorf("SignReport = %v, want %v", err, domain.ErrSigningRefused)
+			}
+		})
+	}
+
+	if _, err := NewServer(newTestSigner(t), Policy{Bounds: map[string]PriceBounds{"ETH/USD": {Min: high, Max: low}}}, testToken); err == nil {
+		t.Errorf("NewServer with a minimum above the maximum succeeded")
+	}
+}
+
+func TestServerDeviationFollowsMarket(t *testing.T) {
+	_, clock, client := newTestServer(t, Policy{MaxDeviation: 0.2, DeviationWindow: 10 * time.Minute})
+	ctx := context.Background()
+
+	if _, err := client.SignReport(ctx, testReport(t, "ETH/USD", "3000")); err != nil {
+		t.Fatalf("SignReport: %v", err)
+	}
+	if _, err := client.SignReport(ctx, testReport(t, "ETH/USD", "3500")); err != nil {
+		t.Fatalf("SignReport within the deviation: %v", err)
+	}
+
+	// The market moves by half, the signer refuses until the last signed price expires
+	clock.Add(5 * time.Minute)
+	if _, err := client.SignReport(ctx, testReport(t, "ETH/USD", "1750")); !errors.Is(err, domain.ErrSigningRefused) {
+		t.Errorf("SignReport beyond the deviation = %v, want %v", err, domain.ErrSigningRefused)
+	}
+	clock.Add(5*time.Minute + time.Second)
+	if _, err := client.SignReport(ctx, testReport(t, "ETH/USD", "1750")); err != nil {
+		t.Fatalf("SignReport after the deviation window: %v", err)
+	}
+
+	// The new price is the reference
+	if _, err := client.SignReport(ctx, testReport(t, "ETH/USD", "3500")); !errors.Is(err, domain.ErrSigningRefused) {
+		t.Errorf("SignReport back at the old price = %v, want %v", err, domain.ErrSigningRefused)
+	}
+
+	if _, err := NewServer(newTestSigner(t), Policy{MaxDeviation: 0.2}, testToken); err == nil {
+		t.Errorf("NewServer with a deviation without a window succeeded")
+	}
+}
+
+func TestClientRejectsInvalidSignature(t *testing.T) {
+	signer, other := newTestSigner(t), newTestSigner(t)
+	// The server announces the identity of the signer, but signs with another key
+	mux := http.NewServeMux()
+	mux.HandleFunc(identityPath, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, identityResponse{ID: signer.ID(), PublicKey: signer.PublicKey()})
+	})
+	mux.HandleFunc(signPath, func(w http.ResponseWriter, r *http.Request) {
+		report := testReport(t, "ETH/USD", "3456.78")
+		signature, err := other.SignReport(r.Context(), report)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, signResponse{Signature: signature})
+	})
+	httpServer := httptest.NewServer(mux)
+	defer httpServer.Close()
+
+	client, err := NewRemoteSigner(context.Background(), httpServer.URL, testToken, 5*time.Second)
+	if err != nil {
+		t.Fatalf("NewRemoteSigner: %v", err)
+	}
+	if _, err := client.SignReport(context.Background(), testReport(t, "ETH/USD", "3456.78")); err == nil {
+		t.Errorf("SignReport accepted a signature of another key")
+	}
+
+	// An identity that is not derived from its public key is refused
+	mux = http.NewServeMux()
+	mux.HandleFunc(identityPath, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, identityResponse{ID: other.ID(), PublicKey: signer.PublicKey()})
+	})
+	impostor := httptest.NewServer(mux)
+	defer impostor.Close()
+	if _, err := NewRemoteSigner(context.Background(), impostor.URL, testToken, 5*time.Second); err == nil {
+		t.Errorf("NewRemoteSigner accepted an identity of another key")
+	}
+}
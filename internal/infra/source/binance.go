@@ -0,0 +1,52 @@
+package source
+
+import (
+	"context"
+	"net/url"
+
+	"chainlink-lite/internal/app/domain"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Binance fetches the last traded price of a spot symbol from the Binance API
+// The symbol of a feed defaults to its assets concatenated, with USD traded as USDT, e.g. ETHUSDT for ETH/USD
+type Binance struct {
+	url     string
+	symbols map[string]string // Binance symbol, by feed
+}
+
+var _ domain.PriceSource = (*Binance)(nil)
+
+// NewBinance creates the Binance source, symbols overrides the symbol of some feeds
+// url is the base URL of the API, e.g. https://api.binance.com
+func NewBinance(url string, symbols map[string]string) *Binance {
+	return &Binance{url: trimURL(url), symbols: symbols}
+}
+
+// Fetch the last price of the symbol of the feed
+func (b *Binance) FetchPrice(ctx context.Context, feed string) (domain.Price, error) {
+	symbol, err := symbolOf(b.symbols, feed, func(base, quote string) string {
+		if quote == "USD" {
+			quote = "USDT"
+		}
+		return base + quote
+	})
+	if err != nil {
+		return domain.Price{}, err
+	}
+
+	var result struct {
+		Symbol string `json:"symbol"`
+		Price  string `json:"price"`
+	}
+	query := url.Values{"symbol": {symbol}}
+	if err := getJSON(ctx, b.url+"/api/v3/ticker/price?"+query.Encode(), &result); err != nil {
+		return domain.Price{}, err
+	}
+	if result.Price == "" {
+		log.Debugf("No price of %s in the Binance response", symbol)
+		return domain.Price{}, domain.ErrFailedToFetchPrice
+	}
+	return decimal(result.Price)
+}
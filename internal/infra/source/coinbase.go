@@ -0,0 +1,47 @@
+package source
+
+import (
+	"context"
+	"net/url"
+
+	"chainlink-lite/internal/app/domain"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Coinbase fetches the last traded price of a product from the Coinbase Exchange API
+// The product of a feed defaults to its assets joined by a dash, e.g. ETH-USD for ETH/USD
+type Coinbase struct {
+	url      string
+	products map[string]string // Coinbase product ID, by feed
+}
+
+var _ domain.PriceSource = (*Coinbase)(nil)
+
+// NewCoinbase creates the Coinbase source, products overrides the product of some feeds
+// url is the base URL of the API, e.g. https://api.exchange.coinbase.com
+func NewCoinbase(url string, products map[string]string) *Coinbase {
+	return &Coinbase{url: trimURL(url), products: products}
+}
+
+// Fetch the last price of the product of the feed
+func (c *Coinbase) FetchPrice(ctx context.Context, feed string) (domain.Price, error) {
+	product, err := symbolOf(c.products, feed, func(base, quote string) string {
+		return base + "-" + quote
+	})
+	if err != nil {
+		return domain.Price{}, err
+	}
+
+	var result struct {
+		Price string `json:"price"`
+	}
+	if err := getJSON(ctx, c.url+"/products/"+url.PathEscape(product)+"/ticker", &result); err != nil {
+		return domain.Price{}, err
+	}
+	if result.Price == "" {
+		log.Debugf("No price of %s in the Coinbase response", product)
+		return domain.Price{}, domain.ErrFailedToFetchPrice
+	}
+	return decimal(result.Price)
+}
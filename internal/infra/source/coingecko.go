@@ -0,0 +1,58 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"chainlink-lite/internal/app/domain"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// CoinGecko fetches prices from the simple price API of CoinGecko
+// The base asset of a feed is looked up by its CoinGecko ID, and the quote asset is the vs currency,
+// e.g. ethereum in usd for ETH/USD, or chainlink in eth for LINK/ETH
+type CoinGecko struct {
+	url string
+	ids map[string]string // CoinGecko ID of the base asset, by feed
+}
+
+// Response is the price of every requested ID in every requested vs currency
+type Response map[string]map[string]json.Number
+
+var _ domain.PriceSource = (*CoinGecko)(nil)
+
+// NewCoinGecko creates the CoinGecko source of the feeds, with the CoinGecko ID of their base asset
+// url is the base URL of the API, e.g. https://api.coingecko.com/api/v3
+func NewCoinGecko(url string, ids map[string]string) *CoinGecko {
+	return &CoinGecko{url: trimURL(url), ids: ids}
+}
+
+// Fetch the price of the base asset of the feed in its quote asset
+func (c *CoinGecko) FetchPrice(ctx context.Context, feed string) (domain.Price, error) {
+	id, ok := c.ids[feed]
+	if !ok || id == "" {
+		return domain.Price{}, fmt.Errorf("%w: no CoinGecko ID for %s", domain.ErrUnsupportedFeed, feed)
+	}
+	_, quote, err := domain.ParseFeed(feed)
+	if err != nil {
+		return domain.Price{}, err
+	}
+	currency := strings.ToLower(quote)
+
+	query := url.Values{"ids": {id}, "vs_currencies": {currency}}
+	var result Response
+	if err := getJSON(ctx, c.url+"/simple/price?"+query.Encode(), &result); err != nil {
+		return domain.Price{}, err
+	}
+
+	price, ok := result[id][currency]
+	if !ok {
+		log.Debugf("No price of %s in %s in the response", id, currency)
+		return domain.Price{}, domain.ErrFailedToFetchPrice
+	}
+	return decimal(string(price))
+}
@@ -0,0 +1,46 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+
+	"chainlink-lite/internal/app/domain"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// CryptoCompare fetches the aggregated price of an asset from the CryptoCompare API
+// The base asset of a feed is the from symbol, and its quote asset the to symbol
+type CryptoCompare struct {
+	url string
+}
+
+var _ domain.PriceSource = (*CryptoCompare)(nil)
+
+// NewCryptoCompare creates the CryptoCompare source
+// url is the base URL of the API, e.g. https://min-api.cryptocompare.com
+func NewCryptoCompare(url string) *CryptoCompare {
+	return &CryptoCompare{url: trimURL(url)}
+}
+
+// Fetch the price of the base asset of the feed in its quote asset
+func (c *CryptoCompare) FetchPrice(ctx context.Context, feed string) (domain.Price, error) {
+	base, quote, err := domain.ParseFeed(feed)
+	if err != nil {
+		return domain.Price{}, err
+	}
+
+	// Failures are reported in a successful response, without the price
+	var result map[string]interface{}
+	query := url.Values{"fsym": {base}, "tsyms": {quote}}
+	if err := getJSON(ctx, c.url+"/data/price?"+query.Encode(), &result); err != nil {
+		return domain.Price{}, err
+	}
+	price, ok := result[quote].(json.Number)
+	if !ok {
+		log.Debugf("No price of %s in %s in the CryptoCompare response: %v", base, quote, result["Message"])
+		return domain.Price{}, domain.ErrFailedToFetchPrice
+	}
+	return decimal(string(price))
+}
@@ -0,0 +1,94 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"chainlink-lite/internal/app/domain"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// getJSON decodes the JSON body of a GET request to url into out
+func getJSON(ctx context.Context, rawURL string, out interface{}) error {
+	return doJSON(ctx, http.MethodGet, rawURL, nil, nil, rawURL, out)
+}
+
+// doJSON sends the request and decodes its JSON body into out, numbers are decoded as json.Number
+// Any transport, status or decoding failure is reported as ErrFailedToFetchPrice, the cause is logged with label,
+// so URLs carrying an API key are not logged
+func doJSON(ctx context.Context, method string, rawURL string, header http.Header, body io.Reader, label string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, body)
+	if err != nil {
+		log.Debugf("Failed to build the request of %s: %v", label, causeOf(err))
+		return domain.ErrFailedToFetchPrice
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	if req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", "application/json")
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		log.Debugf("Failed to request %s: %v", label, causeOf(err))
+		return domain.ErrFailedToFetchPrice
+	}
+	defer resp.Body.Close()
+
+	// Check if the response status code is not 200
+	if resp.StatusCode != http.StatusOK {
+		log.Debugf("Status code of %s is %d", label, resp.StatusCode)
+		return domain.ErrFailedToFetchPrice
+	}
+
+	decoder := json.NewDecoder(resp.Body)
+	decoder.UseNumber() // Use json.Number to avoid floating point precision issues
+	if err := decoder.Decode(out); err != nil {
+		log.Debugf("Failed to decode the response of %s: %v", label, err)
+		return domain.ErrFailedToFetchPrice
+	}
+	return nil
+}
+
+// causeOf returns the cause of a request error without the URL it was sent to, which may carry an API key
+func causeOf(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+	return err
+}
+
+// decimal returns the exact price of the decimal returned by an API
+func decimal(s string) (domain.Price, error) {
+	price, err := domain.ParseDecimal(s)
+	if err != nil {
+		log.Debugf("Invalid price in the response: %v", err)
+		return domain.Price{}, fmt.Errorf("%w: %v", domain.ErrFailedToFetchPrice, err)
+	}
+	return price, nil
+}
+
+// symbolOf returns the configured symbol of the feed, or the symbol derived from its assets by format
+func symbolOf(symbols map[string]string, feed string, format func(base, quote string) string) (string, error) {
+	if symbol, ok := symbols[feed]; ok && symbol != "" {
+		return symbol, nil
+	}
+	base, quote, err := domain.ParseFeed(feed)
+	if err != nil {
+		return "", err
+	}
+	return format(base, quote), nil
+}
+
+// trimURL removes the trailing slashes of a base URL
+func trimURL(url string) string {
+	return strings.TrimRight(url, "/")
+}
@@ -0,0 +1,251 @@
+package source
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"text/template"
+
+	"chainlink-lite/internal/app/domain"
+
+	"github.com/jmespath/go-jmespath"
+	log "github.com/sirupsen/logrus"
+)
+
+// HTTPSpec declares a price source on any HTTP API answering JSON, so a provider can be added without code
+// URL, Headers, Body and Path are Go templates of the request of a feed, see HTTPRequest
+type HTTPSpec struct {
+	Name       string            // Name of the source in logs and in the symbols of the feeds
+	Method     string            // HTTP method of the request, GET if empty
+	URL        string            // URL of the request
+	Headers    map[string]string // Headers of the request
+	Body       string            // Body of the request, not sent if empty
+	Path       string            // JMESPath expression of the price in the response, e.g. data.amount or result.*.c[0] | [0]
+	Multiplier string            // Decimal the price is multiplied by, e.g. 0.01 for a price in cents, 1 if empty
+	APIKeyEnv  string            // Environment variable the API key is read from, if the API requires one
+	Feeds      []string          // Feeds the source serves, every feed if empty
+	Symbols    map[string]string // Symbol of the feeds on the API, BASEQUOTE by default
+}
+
+// HTTPRequest is the data the templates of an HTTP source are executed with,
+// e.g. {{.Symbol}}, {{lower .Base}} or {{.APIKey}}
+type HTTPRequest struct {
+	Feed   string // Feed, e.g. ETH/USD
+	Base   string // Base asset of the feed, e.g. ETH
+	Quote  string // Quote asset of the feed, e.g. USD
+	Symbol string // Symbol of the feed on the API
+	APIKey string // API key of the source
+}
+
+// HTTPSource fetches prices from an API declared by an HTTPSpec
+type HTTPSource struct {
+	name       string
+	request    *httpTemplates
+	path       *template.Template
+	multiplier domain.Price
+	apiKey     string
+	feeds      map[string]struct{}
+	symbols    map[string]string
+}
+
+// httpTemplates are the templates of the request of an HTTP source or task
+type httpTemplates struct {
+	method  string
+	url     *template.Template
+	headers map[string]*template.Template
+	body    *template.Template
+}
+
+var _ domain.PriceSource = (*HTTPSource)(nil)
+
+// templateFuncs are the functions available to the templates of an HTTP source
+var templateFuncs = template.FuncMap{"lower": strings.ToLower, "upper": strings.ToUpper}
+
+// NewHTTPSource creates the source declared by the spec
+// The API key is read from the environment once, and the source is refused if it is not set
+func NewHTTPSource(spec HTTPSpec) (*HTTPSource, error) {
+	if spec.Name == "" {
+		return nil, fmt.Errorf("http source has no name")
+	}
+	if spec.URL == "" || spec.Path == "" {
+		return nil, fmt.Errorf("http source %s needs a url and a path", spec.Name)
+	}
+	request, err := newHTTPTemplates("http source "+spec.Name, spec.Method, spec.URL, spec.Headers, spec.Body)
+	if err != nil {
+		return nil, err
+	}
+	path, err := parseTemplate("http source "+spec.Name, "path", spec.Path)
+	if err != nil {
+		return nil, err
+	}
+	apiKey, err := apiKeyOf("http source "+spec.Name, spec.APIKeyEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &HTTPSource{
+		name:    spec.Name,
+		request: request,
+		path:    path,
+		apiKey:  apiKey,
+		feeds:   make(map[string]struct{}, len(spec.Feeds)),
+		symbols: spec.Symbols,
+	}
+	if spec.Multiplier != "" {
+		s.multiplier, err = domain.ParseDecimal(spec.Multiplier)
+		if err != nil || s.multiplier.Sign() <= 0 {
+			return nil, fmt.Errorf("http source %s: multiplier %q is not a positive decimal", spec.Name, spec.Multiplier)
+		}
+	}
+	for _, feed := range spec.Feeds {
+		if _, _, err := domain.ParseFeed(feed); err != nil {
+			return nil, fmt.Errorf("http source %s: %w", spec.Name, err)
+		}
+		s.feeds[feed] = struct{}{}
+	}
+	return s, nil
+}
+
+// newHTTPTemplates parses the templates of a request, owner names the source or task in errors
+func newHTTPTemplates(owner string, method string, url string, headers map[string]string, body string) (*httpTemplates, error) {
+	t := &httpTemplates{
+		method:  strings.ToUpper(method),
+		headers: make(map[string]*template.Template, len(headers)),
+	}
+	if t.method == "" {
+		t.method = http.MethodGet
+	}
+	if t.method != http.MethodGet && t.method != http.MethodPost {
+		return nil, fmt.Errorf("%s: unsupported method %s", owner, method)
+	}
+	var err error
+	if t.url, err = parseTemplate(owner, "url", url); err != nil {
+		return nil, err
+	}
+	if body != "" {
+		if t.body, err = parseTemplate(owner, "body", body); err != nil {
+			return nil, err
+		}
+	}
+	for key, value := range headers {
+		if t.headers[key], err = parseTemplate(owner, "header "+key, value); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// do sends the request of the templates for the data and decodes its JSON body into out
+func (t *httpTemplates) do(ctx context.Context, data HTTPRequest, label string, out interface{}) error {
+	url, err := execute(t.url, data)
+	if err != nil {
+		return err
+	}
+	header := make(http.Header, len(t.headers))
+	for key, value := range t.headers {
+		text, err := execute(value, data)
+		if err != nil {
+			return err
+		}
+		header.Set(key, text)
+	}
+	var body io.Reader
+	if t.body != nil {
+		text, err := execute(t.body, data)
+		if err != nil {
+			return err
+		}
+		body = strings.NewReader(text)
+		if header.Get("Content-Type") == "" {
+			header.Set("Content-Type", "application/json")
+		}
+	}
+	return doJSON(ctx, t.method, url, header, body, label, out)
+}
+
+// apiKeyOf reads the API key from the environment variable, if any, and refuses an unset variable
+func apiKeyOf(owner string, env string) (string, error) {
+	if env == "" {
+		return "", nil
+	}
+	apiKey := os.Getenv(env)
+	if apiKey == "" {
+		return "", fmt.Errorf("%s: API key variable %s is not set", owner, env)
+	}
+	return apiKey, nil
+}
+
+// parseTemplate parses a template of a source or task, missing fields are errors rather than empty strings
+func parseTemplate(owner string, name string, text string) (*template.Template, error) {
+	t, err := template.New(name).Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("%s: invalid %s template: %v", owner, name, err)
+	}
+	return t, nil
+}
+
+// Fetch the price of the feed at the path of the response of the API, multiplied by the multiplier
+func (s *HTTPSource) FetchPrice(ctx context.Context, feed string) (domain.Price, error) {
+	if _, ok := s.feeds[feed]; len(s.feeds) > 0 && !ok {
+		return domain.Price{}, fmt.Errorf("%w: %s is not served by %s", domain.ErrUnsupportedFeed, feed, s.name)
+	}
+	base, quote, err := domain.ParseFeed(feed)
+	if err != nil {
+		return domain.Price{}, err
+	}
+	symbol, ok := s.symbols[feed]
+	if !ok || symbol == "" {
+		symbol = base + quote
+	}
+	request := HTTPRequest{Feed: feed, Base: base, Quote: quote, Symbol: symbol, APIKey: s.apiKey}
+
+	path, err := execute(s.path, request)
+	if err != nil {
+		return domain.Price{}, err
+	}
+	var response interface{}
+	label := fmt.Sprintf("%s price of %s", s.name, feed)
+	if err := s.request.do(ctx, request, label, &response); err != nil {
+		return domain.Price{}, err
+	}
+	value, err := jmespath.Search(path, response)
+	if err != nil {
+		return domain.Price{}, fmt.Errorf("http source %s: invalid path %q: %v", s.name, path, err)
+	}
+
+	price, err := priceOf(value)
+	if err != nil {
+		log.Debugf("No price of %s at %s in the response of %s: %v", feed, path, s.name, value)
+		return domain.Price{}, err
+	}
+	if s.multiplier.IsZero() {
+		return price, nil
+	}
+	return price.Mul(s.multiplier)
+}
+
+// priceOf returns the price of a value extracted from a JSON document, a number or a decimal string
+func priceOf(value interface{}) (domain.Price, error) {
+	switch v := value.(type) {
+	case json.Number:
+		return decimal(string(v))
+	case string:
+		return decimal(v)
+	default:
+		return domain.Price{}, domain.ErrFailedToFetchPrice
+	}
+}
+
+// execute returns the text of the template for the request
+func execute(t *template.Template, request HTTPRequest) (string, error) {
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, request); err != nil {
+		return "", fmt.Errorf("%w: %v", domain.ErrFailedToFetchPrice, err)
+	}
+	return buf.String(), nil
+}
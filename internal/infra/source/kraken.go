@@ -0,0 +1,63 @@
+package source
+
+import (
+	"context"
+	"net/url"
+
+	"chainlink-lite/internal/app/domain"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Kraken fetches the last traded price of a pair from the public ticker of the Kraken API
+// The pair of a feed defaults to its assets concatenated, e.g. ETHUSD for ETH/USD
+type Kraken struct {
+	url   string
+	pairs map[string]string // Kraken pair, by feed
+}
+
+var _ domain.PriceSource = (*Kraken)(nil)
+
+// NewKraken creates the Kraken source, pairs overrides the pair of some feeds
+// url is the base URL of the API, e.g. https://api.kraken.com
+func NewKraken(url string, pairs map[string]string) *Kraken {
+	return &Kraken{url: trimURL(url), pairs: pairs}
+}
+
+// Fetch the price of the last trade of the pair of the feed
+func (k *Kraken) FetchPrice(ctx context.Context, feed string) (domain.Price, error) {
+	pair, err := symbolOf(k.pairs, feed, func(base, quote string) string {
+		return base + quote
+	})
+	if err != nil {
+		return domain.Price{}, err
+	}
+
+	// Kraken answers with its own name of the pair, e.g. XETHZUSD for ETHUSD,
+	// and reports failures in the error list of a successful response
+	var result struct {
+		Error  []string `json:"error"`
+		Result map[string]struct {
+			Close []string `json:"c"` // Price and volume of the last trade
+		} `json:"result"`
+	}
+	query := url.Values{"pair": {pair}}
+	if err := getJSON(ctx, k.url+"/0/public/Ticker?"+query.Encode(), &result); err != nil {
+		return domain.Price{}, err
+	}
+	if len(result.Error) > 0 {
+		log.Debugf("Kraken failed to return the price of %s: %v", pair, result.Error)
+		return domain.Price{}, domain.ErrFailedToFetchPrice
+	}
+	if len(result.Result) != 1 {
+		log.Debugf("Kraken returned %d pairs for %s", len(result.Result), pair)
+		return domain.Price{}, domain.ErrFailedToFetchPrice
+	}
+	for _, ticker := range result.Result {
+		if len(ticker.Close) > 0 && ticker.Close[0] != "" {
+			return decimal(ticker.Close[0])
+		}
+	}
+	log.Debugf("No price of %s in the Kraken response", pair)
+	return domain.Price{}, domain.ErrFailedToFetchPrice
+}
@@ -0,0 +1,93 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"chainlink-lite/internal/app/domain"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Median fetches the price of a feed from several sources in parallel and returns their median,
+// so a single source being down, rate limited or off the market does not stop the node from observing
+type Median struct {
+	sources    map[string]domain.PriceSource // Sources, by name
+	minSources int                           // Sources that must return a price
+	timeout    time.Duration                 // Time given to every source to return a price
+}
+
+var _ domain.PriceSource = (*Median)(nil)
+
+// NewMedian creates the median of the sources, that requires minSources of them to return a price
+func NewMedian(sources map[string]domain.PriceSource, minSources int, timeout time.Duration) (*Median, error) {
+	if minSources < 1 || minSources > len(sources) {
+		return nil, fmt.Errorf("minimum of %d sources out of %d", minSources, len(sources))
+	}
+	if timeout <= 0 {
+		return nil, fmt.Errorf("timeout %v must be positive", timeout)
+	}
+	return &Median{sources: sources, minSources: minSources, timeout: timeout}, nil
+}
+
+// sourcePrice is the price returned by a source
+type sourcePrice struct {
+	source string
+	price  domain.Price
+	err    error
+}
+
+// Fetch the price of the feed from every source, and return the median of the prices once every source answered
+// or the timeout elapsed, provided at least the minimum number of sources returned a price
+func (m *Median) FetchPrice(ctx context.Context, feed string) (domain.Price, error) {
+	ctx, cancel := context.WithTimeout(ctx, m.timeout)
+	defer cancel()
+
+	results := make(chan sourcePrice, len(m.sources))
+	for name, source := range m.sources {
+		go func(name string, source domain.PriceSource) {
+			price, err := source.FetchPrice(ctx, feed)
+			if err == nil && price.Sign() <= 0 {
+				err = fmt.Errorf("%w: invalid price %q", domain.ErrFailedToFetchPrice, price.String())
+			}
+			results <- sourcePrice{source: name, price: price, err: err}
+		}(name, source)
+	}
+
+	var prices []sourcePrice
+	supported := 0
+	for range m.sources {
+		result := <-results // Every source returns once the context is done
+		if errors.Is(result.err, domain.ErrUnsupportedFeed) {
+			continue
+		}
+		supported++
+		if result.err != nil {
+			log.Debugf("Source %s failed to return the price of %s: %v", result.source, feed, result.err)
+			continue
+		}
+		prices = append(prices, result)
+	}
+
+	if supported == 0 {
+		return domain.Price{}, fmt.Errorf("%w: no source of %s", domain.ErrUnsupportedFeed, feed)
+	}
+	if len(prices) < m.minSources {
+		log.Warnf("%d of %d sources returned the price of %s, %d required", len(prices), supported, feed, m.minSources)
+		return domain.Price{}, fmt.Errorf("%w: %d of %d sources returned a price, %d required",
+			domain.ErrFailedToFetchPrice, len(prices), supported, m.minSources)
+	}
+
+	// Order the prices by source, so ties are broken the same way whatever the order the sources answered in
+	sort.Slice(prices, func(i, j int) bool { return prices[i].source < prices[j].source })
+	answers := make([]domain.Price, len(prices))
+	for i, result := range prices {
+		answers[i] = result.price
+	}
+	median := prices[domain.MedianIndex(answers)]
+	log.Debugf("Median price of %s from %d sources is %s of %s", feed, len(prices), median.price, median.source)
+	return median.price, nil
+}
@@ -0,0 +1,24 @@
+package source
+
+import (
+	"chainlink-lite/internal/app/domain"
+	"context"
+	"math/big"
+	"math/rand"
+)
+
+type MockSource struct {
+}
+
+var _ domain.PriceSource = (*MockSource)(nil)
+
+func NewMockSource() *MockSource {
+	return &MockSource{}
+}
+
+// Return a random price with 2 decimals, whatever the feed
+func (m *MockSource) FetchPrice(_ context.Context, _ string) (domain.Price, error) {
+	// Returns a random price between 0.01 and 4000.00
+	answer := big.NewInt(rand.Int63n(400000) + 1)
+	return domain.NewPrice(answer, 2)
+}
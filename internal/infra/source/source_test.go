@@ -0,0 +1,481 @@
+package source
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"chainlink-lite/internal/app/domain"
+	"chainlink-lite/internal/app/service"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// fixtureServer serves the recorded response of the API at path, and fails the test on any other request
+func fixtureServer(t *testing.T, path string, query map[string]string, fixture string) *httptest.Server {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("testdata", fixture))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != path {
+			t.Errorf("path = %s, want %s", r.URL.Path, path)
+		}
+		for key, value := range query {
+			if got := r.URL.Query().Get(key); got != value {
+				t.Errorf("query %s = %s, want %s", key, got, value)
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestSources(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		query   map[string]string
+		fixture string
+		source  func(url string) domain.PriceSource
+		want    string
+	}{
+		{
+			name:    "coingecko",
+			path:    "/simple/price",
+			query:   map[string]string{"ids": "ethereum", "vs_currencies": "usd"},
+			fixture: "coingecko_simple_price.json",
+			source: func(url string) domain.PriceSource {
+				return NewCoinGecko(url, map[string]string{"ETH/USD": "ethereum"})
+			},
+			want: "3456.78",
+		},
+		{
+			name:    "binance",
+			path:    "/api/v3/ticker/price",
+			query:   map[string]string{"symbol": "ETHUSDT"},
+			fixture: "binance_ticker_price.json",
+			source:  func(url string) domain.PriceSource { return NewBinance(url, nil) },
+			want:    "3457.12000000",
+		},
+		{
+			name:    "coinbase",
+			path:    "/products/ETH-USD/ticker",
+			fixture: "coinbase_ticker.json",
+			source:  func(url string) domain.PriceSource { return NewCoinbase(url, nil) },
+			want:    "3456.91",
+		},
+		{
+			name:    "kraken",
+			path:    "/0/public/Ticker",
+			query:   map[string]string{"pair": "ETHUSD"},
+			fixture: "kraken_ticker.json",
+			source:  func(url string) domain.PriceSource { return NewKraken(url, nil) },
+			want:    "3456.49000",
+		},
+		{
+			name:    "cryptocompare",
+			path:    "/data/price",
+			query:   map[string]string{"fsym": "ETH", "tsyms": "USD"},
+			fixture: "cryptocompare_price.json",
+			source:  func(url string) domain.PriceSource { return NewCryptoCompare(url) },
+			want:    "3456.85",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := fixtureServer(t, tt.path, tt.query, tt.fixture)
+			price, err := tt.source(server.URL).FetchPrice(context.Background(), "ETH/USD")
+			if err != nil {
+				t.Fatalf("FetchPrice: %v", err)
+			}
+			if price.String() != tt.want {
+				t.Errorf("price = %s, want %s", price, tt.want)
+			}
+		})
+	}
+}
+
+func TestSymbolOverride(t *testing.T) {
+	server := fixtureServer(t, "/0/public/Ticker", map[string]string{"pair": "XBTUSD"}, "kraken_ticker.json")
+	source := NewKraken(server.URL, map[string]string{"BTC/USD": "XBTUSD"})
+	if _, err := source.FetchPrice(context.Background(), "BTC/USD"); err != nil {
+		t.Fatalf("FetchPrice: %v", err)
+	}
+}
+
+func TestSourceErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		source func(t *testing.T) domain.PriceSource
+		want   error
+	}{
+		{
+			name: "kraken error list",
+			source: func(t *testing.T) domain.PriceSource {
+				return NewKraken(fixtureServer(t, "/0/public/Ticker", nil, "kraken_unknown_pair.json").URL, nil)
+			},
+			want: domain.ErrFailedToFetchPrice,
+		},
+		{
+			name: "cryptocompare error response",
+			source: func(t *testing.T) domain.PriceSource {
+				return NewCryptoCompare(fixtureServer(t, "/data/price", nil, "cryptocompare_error.json").URL)
+			},
+			want: domain.ErrFailedToFetchPrice,
+		},
+		{
+			name: "status code",
+			source: func(t *testing.T) domain.PriceSource {
+				server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					http.Error(w, `{"code":-1003,"msg":"Too many requests"}`, http.StatusTooManyRequests)
+				}))
+				t.Cleanup(server.Close)
+				return NewBinance(server.URL, nil)
+			},
+			want: domain.ErrFailedToFetchPrice,
+		},
+		{
+			name: "coingecko without ID",
+			source: func(t *testing.T) domain.PriceSource {
+				return NewCoinGecko("http://127.0.0.1:0", map[string]string{})
+			},
+			want: domain.ErrUnsupportedFeed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.source(t).FetchPrice(context.Background(), "ETH/USD")
+			if !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+// staticSource returns a fixed price or error, after a delay
+type staticSource struct {
+	price string
+	err   error
+	delay time.Duration
+}
+
+func (s staticSource) FetchPrice(ctx context.Context, _ string) (domain.Price, error) {
+	select {
+	case <-time.After(s.delay):
+		if s.err != nil {
+			return domain.Price{}, s.err
+		}
+		return domain.ParseDecimal(s.price)
+	case <-ctx.Done():
+		return domain.Price{}, ctx.Err()
+	}
+}
+
+func TestMedian(t *testing.T) {
+	tests := []struct {
+		name       string
+		sources    map[string]domain.PriceSource
+		minSources int
+		want       string
+		wantErr    error
+	}{
+		{
+			name: "median of all sources",
+			sources: map[string]domain.PriceSource{
+				"a": staticSource{price: "3456.10"},
+				"b": staticSource{price: "3500"},
+				"c": staticSource{price: "3457.2"},
+			},
+			minSources: 3,
+			want:       "3457.2",
+		},
+		{
+			name: "failed and slow sources are left out",
+			sources: map[string]domain.PriceSource{
+				"a": staticSource{price: "3456.10"},
+				"b": staticSource{err: domain.ErrFailedToFetchPrice},
+				"c": staticSource{price: "3457.2"},
+				"d": staticSource{price: "1", delay: time.Minute},
+			},
+			minSources: 2,
+			want:       "3457.2",
+		},
+		{
+			name: "unsupported sources do not count",
+			sources: map[string]domain.PriceSource{
+				"a": staticSource{price: "3456.10"},
+				"b": staticSource{err: domain.ErrUnsupportedFeed},
+			},
+			minSources: 1,
+			want:       "3456.10",
+		},
+		{
+			name: "too few sources",
+			sources: map[string]domain.PriceSource{
+				"a": staticSource{price: "3456.10"},
+				"b": staticSource{err: domain.ErrFailedToFetchPrice},
+				"c": staticSource{price: "-1"},
+			},
+			minSources: 2,
+			wantErr:    domain.ErrFailedToFetchPrice,
+		},
+		{
+			name: "no source of the feed",
+			sources: map[string]domain.PriceSource{
+				"a": staticSource{err: domain.ErrUnsupportedFeed},
+			},
+			minSources: 1,
+			wantErr:    domain.ErrUnsupportedFeed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			median, err := NewMedian(tt.sources, tt.minSources, 100*time.Millisecond)
+			if err != nil {
+				t.Fatalf("NewMedian: %v", err)
+			}
+			price, err := median.FetchPrice(context.Background(), "ETH/USD")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if price.String() != tt.want {
+				t.Errorf("price = %s, want %s", price, tt.want)
+			}
+		})
+	}
+}
+
+func TestMedianOfFixtures(t *testing.T) {
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(down.Close)
+
+	median, err := NewMedian(map[string]domain.PriceSource{
+		"coingecko": NewCoinGecko(down.URL, map[string]string{"ETH/USD": "ethereum"}),
+		"binance":   NewBinance(fixtureServer(t, "/api/v3/ticker/price", nil, "binance_ticker_price.json").URL, nil),
+		"coinbase":  NewCoinbase(fixtureServer(t, "/products/ETH-USD/ticker", nil, "coinbase_ticker.json").URL, nil),
+		"kraken":    NewKraken(fixtureServer(t, "/0/public/Ticker", nil, "kraken_ticker.json").URL, nil),
+	}, 3, time.Second)
+	if err != nil {
+		t.Fatalf("NewMedian: %v", err)
+	}
+	price, err := median.FetchPrice(context.Background(), "ETH/USD")
+	if err != nil {
+		t.Fatalf("FetchPrice: %v", err)
+	}
+	if want := "3456.91"; price.String() != want {
+		t.Errorf("price = %s, want %s", price, want)
+	}
+}
+
+func TestNewMedian(t *testing.T) {
+	sources := map[string]domain.PriceSource{"a": staticSource{price: "1"}}
+	if _, err := NewMedian(sources, 2, time.Second); err == nil {
+		t.Error("NewMedian accepted more required sources than configured")
+	}
+	if _, err := NewMedian(sources, 0, time.Second); err == nil {
+		t.Error("NewMedian accepted no required source")
+	}
+	if _, err := NewMedian(sources, 1, 0); err == nil {
+		t.Error("NewMedian accepted no timeout")
+	}
+}
+
+func TestHTTPSource(t *testing.T) {
+	body, err := os.ReadFile(filepath.Join("testdata", "coinbase_spot.json"))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/prices/eth-usd/spot" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		if key := r.Header.Get("X-Api-Key"); key != "secret" {
+			t.Errorf("API key = %q", key)
+		}
+		w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("TEST_API_KEY", "secret")
+
+	source, err := NewHTTPSource(HTTPSpec{
+		Name:       "cents",
+		URL:        server.URL + "/v2/prices/{{lower .Base}}-{{lower .Quote}}/spot",
+		Headers:    map[string]string{"X-Api-Key": "{{.APIKey}}"},
+		Path:       "data.amount",
+		Multiplier: "0.01",
+		APIKeyEnv:  "TEST_API_KEY",
+		Feeds:      []string{"ETH/USD"},
+	})
+	if err != nil {
+		t.Fatalf("NewHTTPSource: %v", err)
+	}
+	price, err := source.FetchPrice(context.Background(), "ETH/USD")
+	if err != nil {
+		t.Fatalf("FetchPrice: %v", err)
+	}
+	if want := "3456.91"; price.String() != want {
+		t.Errorf("price = %s, want %s", price, want)
+	}
+	if _, err := source.FetchPrice(context.Background(), "BTC/USD"); !errors.Is(err, domain.ErrUnsupportedFeed) {
+		t.Errorf("err = %v, want %v", err, domain.ErrUnsupportedFeed)
+	}
+}
+
+func TestHTTPSourcePath(t *testing.T) {
+	server := fixtureServer(t, "/0/public/Ticker", map[string]string{"pair": "ETHUSD"}, "kraken_ticker.json")
+	source, err := NewHTTPSource(HTTPSpec{
+		Name: "kraken",
+		URL:  server.URL + "/0/public/Ticker?pair={{.Symbol}}",
+		Path: "values(result)[0].c[0]",
+	})
+	if err != nil {
+		t.Fatalf("NewHTTPSource: %v", err)
+	}
+	price, err := source.FetchPrice(context.Background(), "ETH/USD")
+	if err != nil {
+		t.Fatalf("FetchPrice: %v", err)
+	}
+	if want := "3456.49000"; price.String() != want {
+		t.Errorf("price = %s, want %s", price, want)
+	}
+
+	missing, err := NewHTTPSource(HTTPSpec{Name: "kraken", URL: server.URL + "/0/public/Ticker?pair=ETHUSD", Path: "result.missing"})
+	if err != nil {
+		t.Fatalf("NewHTTPSource: %v", err)
+	}
+	if _, err := missing.FetchPrice(context.Background(), "ETH/USD"); !errors.Is(err, domain.ErrFailedToFetchPrice) {
+		t.Errorf("err = %v, want %v", err, domain.ErrFailedToFetchPrice)
+	}
+}
+
+func TestHTTPSourceDoesNotLogAPIKey(t *testing.T) {
+	// A closed server fails the request with an error carrying the request URL
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+	t.Setenv("TEST_API_KEY", "secret-api-key")
+
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	level := log.GetLevel()
+	log.SetLevel(log.DebugLevel)
+	t.Cleanup(func() {
+		log.SetOutput(os.Stderr)
+		log.SetLevel(level)
+	})
+
+	source, err := NewHTTPSource(HTTPSpec{
+		Name:      "keyed",
+		URL:       server.URL + "/price?symbol={{.Symbol}}&apikey={{.APIKey}}",
+		Path:      "price",
+		APIKeyEnv: "TEST_API_KEY",
+	})
+	if err != nil {
+		t.Fatalf("NewHTTPSource: %v", err)
+	}
+	if _, err := source.FetchPrice(context.Background(), "ETH/USD"); !errors.Is(err, domain.ErrFailedToFetchPrice) {
+		t.Fatalf("err = %v, want %v", err, domain.ErrFailedToFetchPrice)
+	}
+	if logs.Len() == 0 {
+		t.Fatal("the failed request was not logged")
+	}
+	if strings.Contains(logs.String(), "secret-api-key") {
+		t.Errorf("API key logged: %s", logs.String())
+	}
+}
+
+func TestNewHTTPSource(t *testing.T) {
+	tests := []struct {
+		name string
+		spec HTTPSpec
+	}{
+		{name: "no path", spec: HTTPSpec{Name: "a", URL: "http://localhost"}},
+		{name: "bad method", spec: HTTPSpec{Name: "a", URL: "http://localhost", Path: "price", Method: "DELETE"}},
+		{name: "bad template", spec: HTTPSpec{Name: "a", URL: "http://localhost/{{.Symbol", Path: "price"}},
+		{name: "bad multiplier", spec: HTTPSpec{Name: "a", URL: "http://localhost", Path: "price", Multiplier: "-1"}},
+		{name: "unset API key", spec: HTTPSpec{Name: "a", URL: "http://localhost", Path: "price", APIKeyEnv: "TEST_UNSET_API_KEY"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewHTTPSource(tt.spec); err == nil {
+				t.Error("NewHTTPSource accepted an invalid spec")
+			}
+		})
+	}
+}
+
+func TestPipelineTasks(t *testing.T) {
+	server := fixtureServer(t, "/0/public/Ticker", map[string]string{"pair": "XETHZUSD"}, "kraken_ticker.json")
+	vars := service.TaskVars{Job: "eth-usd", Feed: "ETH/USD", Round: 1}
+
+	fetch, err := NewHTTPTask(domain.TaskSpec{ID: "kraken", Type: domain.TaskHTTP, Params: map[string]interface{}{
+		"url":    server.URL + "/0/public/Ticker?pair={{.Symbol}}",
+		"symbol": "XETHZUSD",
+	}})
+	if err != nil {
+		t.Fatalf("NewHTTPTask: %v", err)
+	}
+	parse, err := NewJSONParseTask(domain.TaskSpec{ID: "price", Type: domain.TaskJSONParse, Inputs: []string{"kraken"},
+		Params: map[string]interface{}{"path": "result.*.c[0] | [0]"}})
+	if err != nil {
+		t.Fatalf("NewJSONParseTask: %v", err)
+	}
+
+	document, err := fetch.Run(context.Background(), vars, nil)
+	if err != nil {
+		t.Fatalf("http: %v", err)
+	}
+	price, err := parse.Run(context.Background(), vars, []service.TaskResult{{Value: document}})
+	if err != nil {
+		t.Fatalf("jsonparse: %v", err)
+	}
+	if want := "3456.49000"; price.(domain.Price).String() != want {
+		t.Errorf("price = %s, want %s", price, want)
+	}
+
+	// A failed input fails the parse, and a document without a price at the path too
+	if _, err := parse.Run(context.Background(), vars, []service.TaskResult{{Err: domain.ErrFailedToFetchPrice}}); !errors.Is(err, domain.ErrFailedToFetchPrice) {
+		t.Errorf("err = %v, want %v", err, domain.ErrFailedToFetchPrice)
+	}
+	empty := map[string]interface{}{"result": map[string]interface{}{}}
+	if _, err := parse.Run(context.Background(), vars, []service.TaskResult{{Value: empty}}); !errors.Is(err, domain.ErrFailedToFetchPrice) {
+		t.Errorf("err = %v, want %v", err, domain.ErrFailedToFetchPrice)
+	}
+}
+
+func TestNewPipelineTasks(t *testing.T) {
+	tests := []struct {
+		name string
+		spec domain.TaskSpec
+	}{
+		{name: "http without url", spec: domain.TaskSpec{ID: "a", Type: domain.TaskHTTP}},
+		{name: "http with input", spec: domain.TaskSpec{ID: "a", Type: domain.TaskHTTP, Inputs: []string{"b"},
+			Params: map[string]interface{}{"url": "http://localhost"}}},
+		{name: "http unknown param", spec: domain.TaskSpec{ID: "a", Type: domain.TaskHTTP,
+			Params: map[string]interface{}{"url": "http://localhost", "path": "price"}}},
+		{name: "jsonparse bad path", spec: domain.TaskSpec{ID: "a", Type: domain.TaskJSONParse, Inputs: []string{"b"},
+			Params: map[string]interface{}{"path": "data.["}}},
+		{name: "jsonparse without input", spec: domain.TaskSpec{ID: "a", Type: domain.TaskJSONParse,
+			Params: map[string]interface{}{"path": "price"}}},
+	}
+	tasks := PipelineTasks()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tasks[tt.spec.Type](tt.spec); err == nil {
+				t.Error("task factory accepted an invalid spec")
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package source
+
+import (
+	"context"
+	"fmt"
+
+	"chainlink-lite/internal/app/domain"
+	"chainlink-lite/internal/app/service"
+
+	"github.com/jmespath/go-jmespath"
+	log "github.com/sirupsen/logrus"
+)
+
+// PipelineTasks returns the factories of the tasks fetching prices from APIs, by task type
+func PipelineTasks() map[string]service.TaskFactory {
+	return map[string]service.TaskFactory{
+		domain.TaskHTTP:      NewHTTPTask,
+		domain.TaskJSONParse: NewJSONParseTask,
+	}
+}
+
+// HTTPTask requests an API answering JSON and returns the decoded document
+// Its url, headers and body are templates of an HTTPRequest for the feed of the job, as for an HTTP source
+type HTTPTask struct {
+	id      string
+	request *httpTemplates
+	symbol  string
+	apiKey  string
+}
+
+// NewHTTPTask creates the http task of the spec
+func NewHTTPTask(spec domain.TaskSpec) (service.Task, error) {
+	var params struct {
+		Method    string            `yaml:"method"`
+		URL       string            `yaml:"url"`
+		Headers   map[string]string `yaml:"headers"`
+		Body      string            `yaml:"body"`
+		APIKeyEnv string            `yaml:"api_key_env"`
+		Symbol    string            `yaml:"symbol"` // Symbol of the feed on the API, BASEQUOTE by default
+	}
+	if err := service.DecodeTaskParams(spec, &params); err != nil {
+		return nil, err
+	}
+	if len(spec.Inputs) != 0 {
+		return nil, fmt.Errorf("http takes no input")
+	}
+	if params.URL == "" {
+		return nil, fmt.Errorf("http needs a url")
+	}
+	owner := "http task " + spec.ID
+	request, err := newHTTPTemplates(owner, params.Method, params.URL, params.Headers, params.Body)
+	if err != nil {
+		return nil, err
+	}
+	apiKey, err := apiKeyOf(owner, params.APIKeyEnv)
+	if err != nil {
+		return nil, err
+	}
+	return &HTTPTask{id: spec.ID, request: request, symbol: params.Symbol, apiKey: apiKey}, nil
+}
+
+// Run requests the API for the feed of the job
+func (t *HTTPTask) Run(ctx context.Context, vars service.TaskVars, _ []service.TaskResult) (interface{}, error) {
+	base, quote, err := domain.ParseFeed(vars.Feed)
+	if err != nil {
+		return nil, err
+	}
+	symbol := t.symbol
+	if symbol == "" {
+		symbol = base + quote
+	}
+	request := HTTPRequest{Feed: vars.Feed, Base: base, Quote: quote, Symbol: symbol, APIKey: t.apiKey}
+
+	var response interface{}
+	label := fmt.Sprintf("task %s of job %s", t.id, vars.Job)
+	if err := t.request.do(ctx, request, label, &response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// JSONParseTask extracts the price at a JMESPath expression of the JSON document of its input
+type JSONParseTask struct {
+	path string
+	expr *jmespath.JMESPath
+}
+
+// NewJSONParseTask creates the jsonparse task of the spec, its path parameter is the JMESPath expression of the price
+func NewJSONParseTask(spec domain.TaskSpec) (service.Task, error) {
+	var params struct {
+		Path string `yaml:"path"`
+	}
+	if err := service.DecodeTaskParams(spec, &params); err != nil {
+		return nil, err
+	}
+	if len(spec.Inputs) != 1 {
+		return nil, fmt.Errorf("jsonparse takes a single input")
+	}
+	expr, err := jmespath.Compile(params.Path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid path %q: %v", params.Path, err)
+	}
+	return &JSONParseTask{path: params.Path, expr: expr}, nil
+}
+
+// Run returns the price at the path of the document
+func (t *JSONParseTask) Run(_ context.Context, vars service.TaskVars, inputs []service.TaskResult) (interface{}, error) {
+	values, err := service.TaskInputs(inputs, 1)
+	if err != nil {
+		return nil, err
+	}
+	value, err := t.expr.Search(values[0])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrFailedToFetchPrice, err)
+	}
+	price, err := priceOf(value)
+	if err != nil {
+		log.Debugf("No price of %s at %s of job %s: %v", vars.Feed, t.path, vars.Job, value)
+		return nil, err
+	}
+	return price, nil
+}
@@ -2,6 +2,8 @@ package util
 
 import (
 	"chainlink-lite/internal/app/domain"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 
 	"github.com/google/uuid"
@@ -24,3 +26,12 @@ func GenerateUUID() (string, error) {
 	}
 	return uuid.String(), nil
 }
+
+// GenerateSalt returns 32 random bytes, hex encoded
+func GenerateSalt() (string, error) {
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(salt), nil
+}